@@ -6,6 +6,8 @@ package main
 import (
 	"fmt"
 	"os"
+	"path/filepath"
+	"strings"
 
 	"github.com/awslabs/diagram-as-code/internal/ctl"
 	log "github.com/sirupsen/logrus"
@@ -26,6 +28,8 @@ func main() {
 	var force bool
 	var width int
 	var height int
+	var freeze bool
+	var theme string
 
 	var rootCmd = &cobra.Command{
 		Use:     "awsdac <input filename>",
@@ -65,6 +69,7 @@ func main() {
 					AllowUntrustedDefinitions: allowUntrustedDefinitions,
 					Width:                     width,
 					Height:                    height,
+					Theme:                     theme,
 				}
 				if force {
 					opts.OverwriteMode = ctl.Force
@@ -82,6 +87,7 @@ func main() {
 					AllowUntrustedDefinitions: allowUntrustedDefinitions,
 					Width:                     width,
 					Height:                    height,
+					Theme:                     theme,
 				}
 				if force {
 					opts.OverwriteMode = ctl.Force
@@ -98,6 +104,48 @@ func main() {
 		},
 	}
 
+	var layoutCmd = &cobra.Command{
+		Use:   "layout <input filename>",
+		Short: "Write the computed layout of a diagram back into DAC.",
+		Long:  "With --freeze, this command writes a copy of the DAC file with explicit sizes, positions and link positions, so that the diagram no longer depends on the automatic layout.",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+
+			if verbose {
+				log.SetLevel(log.InfoLevel)
+			} else {
+				log.SetLevel(log.WarnLevel)
+			}
+
+			if !freeze {
+				return fmt.Errorf("awsdac layout: only --freeze is supported")
+			}
+
+			inputFile := args[0]
+			if !cmd.Flags().Changed("output") {
+				outputFile = strings.TrimSuffix(inputFile, filepath.Ext(inputFile)) + "-frozen.yaml"
+			}
+			opts := ctl.CreateOptions{
+				IsGoTemplate:              isGoTemplate,
+				OverrideDefFile:           overrideDefFile,
+				AllowUntrustedDefinitions: allowUntrustedDefinitions,
+				Theme:                     theme,
+			}
+			if force {
+				opts.OverwriteMode = ctl.Force
+			} else {
+				opts.OverwriteMode = ctl.Ask
+			}
+			if err := ctl.FreezeLayoutFromDacFile(inputFile, outputFile, &opts); err != nil {
+				return fmt.Errorf("failed to freeze layout: %w", err)
+			}
+			fmt.Printf("[Completed] Frozen dac (diagram-as-code) file written to %s\n", outputFile)
+			return nil
+		},
+	}
+	layoutCmd.Flags().BoolVar(&freeze, "freeze", false, "Write a copy of the DAC file with the computed layout made explicit")
+	rootCmd.AddCommand(layoutCmd)
+
 	rootCmd.PersistentFlags().StringVarP(&outputFile, "output", "o", "output.png", "Output file name")
 	rootCmd.PersistentFlags().BoolVarP(&verbose, "verbose", "v", false, "Enable verbose logging")
 	rootCmd.PersistentFlags().BoolVarP(&cfnTemplate, "cfn-template", "c", false, "[beta] Create diagram from CloudFormation template")
@@ -108,6 +156,7 @@ func main() {
 	rootCmd.PersistentFlags().BoolVarP(&force, "force", "f", false, "Overwrite output file without confirmation")
 	rootCmd.PersistentFlags().IntVar(&width, "width", 0, "Resize output image width (0 means no resizing)")
 	rootCmd.PersistentFlags().IntVar(&height, "height", 0, "Resize output image height (0 means no resizing)")
+	rootCmd.PersistentFlags().StringVar(&theme, "theme", "", "Color theme of the diagram (light, dark), overriding the Theme field of the DAC file")
 
 	if err := rootCmd.Execute(); err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
@@ -80,6 +80,12 @@ func CreateDiagramFromCFnTemplate(inputfile string, outputfile *string, generate
 		}
 	}
 
+	template.Theme = opts.Theme
+	theme, err := template.theme()
+	if err != nil {
+		return err
+	}
+
 	var ds definition.DefinitionStructure
 	resources := make(map[string]*types.Resource)
 
@@ -120,6 +126,14 @@ func CreateDiagramFromCFnTemplate(inputfile string, outputfile *string, generate
 	log.Info("--- Ensuring a single parent for resources with multiple parents ---")
 	ensureSingleParent(&template)
 
+	log.Info("--- Resolve colors ---")
+	if err := resolveColors(&template, &ds); err != nil {
+		return fmt.Errorf("failed to resolve colors: %w", err)
+	}
+
+	log.Info("--- Apply theme ---")
+	applyTheme(theme, &ds)
+
 	log.Info("--- Load Resources section ---")
 	if err := loadResources(&template, ds, resources); err != nil {
 		return fmt.Errorf("failed to load resources: %w", err)
@@ -0,0 +1,496 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package ctl
+
+import (
+	"fmt"
+	"image/color"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/awslabs/diagram-as-code/internal/definition"
+	log "github.com/sirupsen/logrus"
+)
+
+// stringToColor parses a color: rgba(r,g,b,a), rgb(r,g,b), hsl(h,s%,l%), hsla(h,s%,l%,a),
+// #RGB, #RGBA, #RRGGBB, #RRGGBBAA or a CSS color name. Alphas are 0-255 as in rgba(), or a
+// fraction such as 0.5 or 50%. Palette references are resolved by resolveColors beforehand.
+func stringToColor(c string) (color.RGBA, error) {
+	s := strings.ToLower(strings.TrimSpace(c))
+	var rgba color.RGBA
+	var err error
+	switch {
+	case strings.HasPrefix(s, "#"):
+		rgba, err = parseHexColor(s[1:])
+	case strings.HasPrefix(s, "rgb"), strings.HasPrefix(s, "hsl"):
+		rgba, err = parseColorFunction(s)
+	case strings.HasPrefix(s, "$"):
+		err = fmt.Errorf("unresolved palette color")
+	default:
+		var ok bool
+		if rgba, ok = namedColors[s]; !ok {
+			err = fmt.Errorf("unknown color name")
+		}
+	}
+	if err != nil {
+		return color.RGBA{}, fmt.Errorf("failed to parse color string '%s': %w", c, err)
+	}
+	return rgba, nil
+}
+
+func parseHexColor(hex string) (color.RGBA, error) {
+	if len(hex) == 3 || len(hex) == 4 {
+		// #RGB is #RRGGBB
+		long := ""
+		for _, r := range hex {
+			long += string(r) + string(r)
+		}
+		hex = long
+	}
+	if len(hex) == 6 {
+		hex += "ff"
+	}
+	if len(hex) != 8 {
+		return color.RGBA{}, fmt.Errorf("expected #RGB, #RGBA, #RRGGBB or #RRGGBBAA")
+	}
+	v, err := strconv.ParseUint(hex, 16, 32)
+	if err != nil {
+		return color.RGBA{}, fmt.Errorf("invalid hexadecimal digits")
+	}
+	return color.RGBA{uint8(v >> 24), uint8(v >> 16), uint8(v >> 8), uint8(v)}, nil
+}
+
+// parseColorFunction parses rgb(), rgba(), hsl() and hsla()
+func parseColorFunction(s string) (color.RGBA, error) {
+	open := strings.Index(s, "(")
+	if open < 0 || !strings.HasSuffix(s, ")") {
+		return color.RGBA{}, fmt.Errorf("expected %s(...)", strings.TrimSpace(s))
+	}
+	name := strings.TrimSpace(s[:open])
+	args := strings.Split(s[open+1:len(s)-1], ",")
+	for i := range args {
+		args[i] = strings.TrimSpace(args[i])
+	}
+	alpha := uint8(255)
+	switch {
+	case (name == "rgb" || name == "hsl") && len(args) == 3:
+	case (name == "rgba" || name == "hsla") && len(args) == 4:
+		a, err := parseAlpha(args[3])
+		if err != nil {
+			return color.RGBA{}, err
+		}
+		alpha = a
+	default:
+		return color.RGBA{}, fmt.Errorf("expected rgb(r,g,b), rgba(r,g,b,a), hsl(h,s%%,l%%) or hsla(h,s%%,l%%,a)")
+	}
+
+	if strings.HasPrefix(name, "rgb") {
+		c := color.RGBA{A: alpha}
+		for i, p := range []*uint8{&c.R, &c.G, &c.B} {
+			v, err := strconv.Atoi(args[i])
+			if err != nil || v < 0 || v > 255 {
+				return color.RGBA{}, fmt.Errorf("%q is not an integer from 0 to 255", args[i])
+			}
+			*p = uint8(v)
+		}
+		return c, nil
+	}
+	h, err := strconv.ParseFloat(args[0], 64)
+	if err != nil {
+		return color.RGBA{}, fmt.Errorf("%q is not a hue in degrees", args[0])
+	}
+	sl := [2]float64{}
+	for i := range sl {
+		arg := args[i+1]
+		v, err := strconv.ParseFloat(strings.TrimSuffix(arg, "%"), 64)
+		if err != nil || !strings.HasSuffix(arg, "%") || v < 0 || v > 100 {
+			return color.RGBA{}, fmt.Errorf("%q is not a percentage", arg)
+		}
+		sl[i] = v / 100
+	}
+	r, g, b := hslToRGB(h, sl[0], sl[1])
+	return color.RGBA{r, g, b, alpha}, nil
+}
+
+// parseAlpha parses an alpha from 0 to 255, or a fraction such as 0.5 or 50%
+func parseAlpha(a string) (uint8, error) {
+	switch {
+	case strings.HasSuffix(a, "%"):
+		v, err := strconv.ParseFloat(strings.TrimSuffix(a, "%"), 64)
+		if err == nil && v >= 0 && v <= 100 {
+			return uint8(math.Round(v * 255 / 100)), nil
+		}
+	case strings.Contains(a, "."):
+		v, err := strconv.ParseFloat(a, 64)
+		if err == nil && v >= 0 && v <= 1 {
+			return uint8(math.Round(v * 255)), nil
+		}
+	default:
+		v, err := strconv.Atoi(a)
+		if err == nil && v >= 0 && v <= 255 {
+			return uint8(v), nil
+		}
+	}
+	return 0, fmt.Errorf("%q is not an alpha from 0 to 255, or a fraction such as 0.5 or 50%%", a)
+}
+
+func hslToRGB(h, s, l float64) (uint8, uint8, uint8) {
+	h = math.Mod(math.Mod(h, 360)+360, 360) / 60
+	chroma := (1 - math.Abs(2*l-1)) * s
+	x := chroma * (1 - math.Abs(math.Mod(h, 2)-1))
+	var r, g, b float64
+	switch int(h) {
+	case 0:
+		r, g = chroma, x
+	case 1:
+		r, g = x, chroma
+	case 2:
+		g, b = chroma, x
+	case 3:
+		g, b = x, chroma
+	case 4:
+		r, b = x, chroma
+	default:
+		r, b = chroma, x
+	}
+	m := l - chroma/2
+	channel := func(v float64) uint8 {
+		return uint8(math.Round((v + m) * 255))
+	}
+	return channel(r), channel(g), channel(b)
+}
+
+// palette maps the names of the Colors sections to their colors
+type palette map[string]string
+
+// newPalette merges the Colors sections, the later ones overriding the earlier ones, and resolves
+// the entries referring to other entries
+func newPalette(sections ...map[string]string) (palette, error) {
+	merged := map[string]string{}
+	for _, section := range sections {
+		for name, value := range section {
+			merged[strings.TrimPrefix(name, "$")] = value
+		}
+	}
+	p := palette{}
+	for _, name := range sortedKeys(merged) {
+		value := merged[name]
+		// References can be chained, but not in a loop
+		for seen := map[string]bool{name: true}; strings.HasPrefix(strings.TrimSpace(value), "$"); {
+			ref := strings.TrimPrefix(strings.TrimSpace(value), "$")
+			next, ok := merged[ref]
+			if !ok {
+				return nil, fmt.Errorf("Colors.%s: unknown palette color $%s", name, ref)
+			}
+			if seen[ref] {
+				return nil, fmt.Errorf("Colors.%s: palette colors refer to each other in a loop", name)
+			}
+			seen[ref] = true
+			value = next
+		}
+		if _, err := stringToColor(value); err != nil {
+			return nil, fmt.Errorf("Colors.%s: %w", name, err)
+		}
+		p[name] = value
+	}
+	return p, nil
+}
+
+// resolve replaces a palette reference in the color field with its color, and checks the color
+func (p palette) resolve(field string, c *string) error {
+	if c == nil || *c == "" {
+		return nil
+	}
+	if ref, ok := strings.CutPrefix(strings.TrimSpace(*c), "$"); ok {
+		value, ok := p[ref]
+		if !ok {
+			return fmt.Errorf("%s: unknown palette color $%s, declare it in the Colors section", field, ref)
+		}
+		*c = value
+	}
+	if _, err := stringToColor(*c); err != nil {
+		return fmt.Errorf("%s: %w", field, err)
+	}
+	return nil
+}
+
+// resolveColors resolves the palette references in every color field of the definitions and of the
+// template, with the Colors sections of the definition files and of the template. It checks the
+// colors up front so that errors name the field. An invalid color of a definition that no resource
+// uses is only logged, as definition files define many more resources than a diagram uses.
+func resolveColors(template *TemplateStruct, ds *definition.DefinitionStructure) error {
+	p, err := newPalette(ds.Colors, template.Colors)
+	if err != nil {
+		return err
+	}
+
+	used := usedDefinitions(template, ds)
+	for _, k := range sortedKeys(ds.Definitions) {
+		def := ds.Definitions[k]
+		if def == nil {
+			continue
+		}
+		if err := p.resolveFields("Definitions."+k, definitionColors(def)); err != nil {
+			if used[k] {
+				return err
+			}
+			log.Warnf("%v (no resource uses the definition)", err)
+		}
+	}
+
+	for _, k := range sortedKeys(template.Resources) {
+		v := template.Resources[k]
+		fields := map[string]*string{
+			"FillColor":   &v.FillColor,
+			"TitleColor":  &v.TitleColor,
+			"BorderColor": &v.BorderColor,
+		}
+		if v.IconFill != nil {
+			fields["IconFill.Color"] = v.IconFill.Color
+		}
+		if v.Fill != nil {
+			fields["Fill.Color"] = &v.Fill.Color
+			fields["Fill.From"] = &v.Fill.From
+			fields["Fill.To"] = &v.Fill.To
+		}
+		if v.Shadow != nil {
+			fields["Shadow.Color"] = &v.Shadow.Color
+		}
+		if err := p.resolveFields("Resources."+k, fields); err != nil {
+			return err
+		}
+		template.Resources[k] = v
+	}
+
+	for i := range template.Links {
+		v := &template.Links[i]
+		fields := map[string]*string{"LineColor": &v.LineColor}
+		labels := map[string]*LinkLabel{
+			"SourceRight": v.Labels.SourceRight,
+			"SourceLeft":  v.Labels.SourceLeft,
+			"TargetRight": v.Labels.TargetRight,
+			"TargetLeft":  v.Labels.TargetLeft,
+			"Center":      v.Labels.Center,
+		}
+		for name, label := range labels {
+			if label != nil {
+				fields["Labels."+name+".Color"] = label.Color
+			}
+		}
+		if err := p.resolveFields(fmt.Sprintf("Links[%d] (%s -> %s)", i, v.Source, v.Target), fields); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// usedDefinitions returns the names of the definitions that the resources of the template use as
+// their type, their fallback service icon or their preset
+func usedDefinitions(template *TemplateStruct, ds *definition.DefinitionStructure) map[string]bool {
+	used := map[string]bool{}
+	for _, v := range template.Resources {
+		if _, ok := ds.Definitions[v.Type]; ok {
+			used[v.Type] = true
+		} else if strings.Contains(v.Type, "::") {
+			used[fallbackToServiceIcon(v.Type)] = true
+		}
+		if v.Preset != "" {
+			used[v.Preset] = true
+		}
+	}
+	return used
+}
+
+// definitionColors returns the color fields of the definition by their names
+func definitionColors(def *definition.Definition) map[string]*string {
+	fields := map[string]*string{}
+	if def.Label != nil {
+		fields["Label.Color"] = &def.Label.Color
+	}
+	if def.Fill != nil {
+		fields["Fill.Color"] = &def.Fill.Color
+		fields["Fill.From"] = &def.Fill.From
+		fields["Fill.To"] = &def.Fill.To
+	}
+	if def.Border != nil {
+		fields["Border.Color"] = &def.Border.Color
+		if def.Border.Shadow != nil {
+			fields["Border.Shadow.Color"] = &def.Border.Shadow.Color
+		}
+	}
+	return fields
+}
+
+func (p palette) resolveFields(prefix string, fields map[string]*string) error {
+	for _, name := range sortedKeys(fields) {
+		if err := p.resolve(prefix+"."+name, fields[name]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// namedColors are the CSS named colors
+var namedColors = map[string]color.RGBA{
+	"transparent":          {0, 0, 0, 0},
+	"aliceblue":            {240, 248, 255, 255},
+	"antiquewhite":         {250, 235, 215, 255},
+	"aqua":                 {0, 255, 255, 255},
+	"aquamarine":           {127, 255, 212, 255},
+	"azure":                {240, 255, 255, 255},
+	"beige":                {245, 245, 220, 255},
+	"bisque":               {255, 228, 196, 255},
+	"black":                {0, 0, 0, 255},
+	"blanchedalmond":       {255, 235, 205, 255},
+	"blue":                 {0, 0, 255, 255},
+	"blueviolet":           {138, 43, 226, 255},
+	"brown":                {165, 42, 42, 255},
+	"burlywood":            {222, 184, 135, 255},
+	"cadetblue":            {95, 158, 160, 255},
+	"chartreuse":           {127, 255, 0, 255},
+	"chocolate":            {210, 105, 30, 255},
+	"coral":                {255, 127, 80, 255},
+	"cornflowerblue":       {100, 149, 237, 255},
+	"cornsilk":             {255, 248, 220, 255},
+	"crimson":              {220, 20, 60, 255},
+	"cyan":                 {0, 255, 255, 255},
+	"darkblue":             {0, 0, 139, 255},
+	"darkcyan":             {0, 139, 139, 255},
+	"darkgoldenrod":        {184, 134, 11, 255},
+	"darkgray":             {169, 169, 169, 255},
+	"darkgreen":            {0, 100, 0, 255},
+	"darkgrey":             {169, 169, 169, 255},
+	"darkkhaki":            {189, 183, 107, 255},
+	"darkmagenta":          {139, 0, 139, 255},
+	"darkolivegreen":       {85, 107, 47, 255},
+	"darkorange":           {255, 140, 0, 255},
+	"darkorchid":           {153, 50, 204, 255},
+	"darkred":              {139, 0, 0, 255},
+	"darksalmon":           {233, 150, 122, 255},
+	"darkseagreen":         {143, 188, 143, 255},
+	"darkslateblue":        {72, 61, 139, 255},
+	"darkslategray":        {47, 79, 79, 255},
+	"darkslategrey":        {47, 79, 79, 255},
+	"darkturquoise":        {0, 206, 209, 255},
+	"darkviolet":           {148, 0, 211, 255},
+	"deeppink":             {255, 20, 147, 255},
+	"deepskyblue":          {0, 191, 255, 255},
+	"dimgray":              {105, 105, 105, 255},
+	"dimgrey":              {105, 105, 105, 255},
+	"dodgerblue":           {30, 144, 255, 255},
+	"firebrick":            {178, 34, 34, 255},
+	"floralwhite":          {255, 250, 240, 255},
+	"forestgreen":          {34, 139, 34, 255},
+	"fuchsia":              {255, 0, 255, 255},
+	"gainsboro":            {220, 220, 220, 255},
+	"ghostwhite":           {248, 248, 255, 255},
+	"gold":                 {255, 215, 0, 255},
+	"goldenrod":            {218, 165, 32, 255},
+	"gray":                 {128, 128, 128, 255},
+	"green":                {0, 128, 0, 255},
+	"greenyellow":          {173, 255, 47, 255},
+	"grey":                 {128, 128, 128, 255},
+	"honeydew":             {240, 255, 240, 255},
+	"hotpink":              {255, 105, 180, 255},
+	"indianred":            {205, 92, 92, 255},
+	"indigo":               {75, 0, 130, 255},
+	"ivory":                {255, 255, 240, 255},
+	"khaki":                {240, 230, 140, 255},
+	"lavender":             {230, 230, 250, 255},
+	"lavenderblush":        {255, 240, 245, 255},
+	"lawngreen":            {124, 252, 0, 255},
+	"lemonchiffon":         {255, 250, 205, 255},
+	"lightblue":            {173, 216, 230, 255},
+	"lightcoral":           {240, 128, 128, 255},
+	"lightcyan":            {224, 255, 255, 255},
+	"lightgoldenrodyellow": {250, 250, 210, 255},
+	"lightgray":            {211, 211, 211, 255},
+	"lightgreen":           {144, 238, 144, 255},
+	"lightgrey":            {211, 211, 211, 255},
+	"lightpink":            {255, 182, 193, 255},
+	"lightsalmon":          {255, 160, 122, 255},
+	"lightseagreen":        {32, 178, 170, 255},
+	"lightskyblue":         {135, 206, 250, 255},
+	"lightslategray":       {119, 136, 153, 255},
+	"lightslategrey":       {119, 136, 153, 255},
+	"lightsteelblue":       {176, 196, 222, 255},
+	"lightyellow":          {255, 255, 224, 255},
+	"lime":                 {0, 255, 0, 255},
+	"limegreen":            {50, 205, 50, 255},
+	"linen":                {250, 240, 230, 255},
+	"magenta":              {255, 0, 255, 255},
+	"maroon":               {128, 0, 0, 255},
+	"mediumaquamarine":     {102, 205, 170, 255},
+	"mediumblue":           {0, 0, 205, 255},
+	"mediumorchid":         {186, 85, 211, 255},
+	"mediumpurple":         {147, 112, 219, 255},
+	"mediumseagreen":       {60, 179, 113, 255},
+	"mediumslateblue":      {123, 104, 238, 255},
+	"mediumspringgreen":    {0, 250, 154, 255},
+	"mediumturquoise":      {72, 209, 204, 255},
+	"mediumvioletred":      {199, 21, 133, 255},
+	"midnightblue":         {25, 25, 112, 255},
+	"mintcream":            {245, 255, 250, 255},
+	"mistyrose":            {255, 228, 225, 255},
+	"moccasin":             {255, 228, 181, 255},
+	"navajowhite":          {255, 222, 173, 255},
+	"navy":                 {0, 0, 128, 255},
+	"oldlace":              {253, 245, 230, 255},
+	"olive":                {128, 128, 0, 255},
+	"olivedrab":            {107, 142, 35, 255},
+	"orange":               {255, 165, 0, 255},
+	"orangered":            {255, 69, 0, 255},
+	"orchid":               {218, 112, 214, 255},
+	"palegoldenrod":        {238, 232, 170, 255},
+	"palegreen":            {152, 251, 152, 255},
+	"paleturquoise":        {175, 238, 238, 255},
+	"palevioletred":        {219, 112, 147, 255},
+	"papayawhip":           {255, 239, 213, 255},
+	"peachpuff":            {255, 218, 185, 255},
+	"peru":                 {205, 133, 63, 255},
+	"pink":                 {255, 192, 203, 255},
+	"plum":                 {221, 160, 221, 255},
+	"powderblue":           {176, 224, 230, 255},
+	"purple":               {128, 0, 128, 255},
+	"rebeccapurple":        {102, 51, 153, 255},
+	"red":                  {255, 0, 0, 255},
+	"rosybrown":            {188, 143, 143, 255},
+	"royalblue":            {65, 105, 225, 255},
+	"saddlebrown":          {139, 69, 19, 255},
+	"salmon":               {250, 128, 114, 255},
+	"sandybrown":           {244, 164, 96, 255},
+	"seagreen":             {46, 139, 87, 255},
+	"seashell":             {255, 245, 238, 255},
+	"sienna":               {160, 82, 45, 255},
+	"silver":               {192, 192, 192, 255},
+	"skyblue":              {135, 206, 235, 255},
+	"slateblue":            {106, 90, 205, 255},
+	"slategray":            {112, 128, 144, 255},
+	"slategrey":            {112, 128, 144, 255},
+	"snow":                 {255, 250, 250, 255},
+	"springgreen":          {0, 255, 127, 255},
+	"steelblue":            {70, 130, 180, 255},
+	"tan":                  {210, 180, 140, 255},
+	"teal":                 {0, 128, 128, 255},
+	"thistle":              {216, 191, 216, 255},
+	"tomato":               {255, 99, 71, 255},
+	"turquoise":            {64, 224, 208, 255},
+	"violet":               {238, 130, 238, 255},
+	"wheat":                {245, 222, 179, 255},
+	"white":                {255, 255, 255, 255},
+	"whitesmoke":           {245, 245, 245, 255},
+	"yellow":               {255, 255, 0, 255},
+	"yellowgreen":          {154, 205, 50, 255},
+}
@@ -0,0 +1,117 @@
+package ctl
+
+import (
+	"image/color"
+	"strings"
+	"testing"
+
+	"github.com/awslabs/diagram-as-code/internal/definition"
+)
+
+func TestStringToColor(t *testing.T) {
+	for _, tt := range []struct {
+		input    string
+		expected color.RGBA
+	}{
+		{"rgba(255,153,0,255)", color.RGBA{255, 153, 0, 255}},
+		{"rgba(105, 59, 197, 255)", color.RGBA{105, 59, 197, 255}},
+		{"rgba(0,0,0,0.5)", color.RGBA{0, 0, 0, 128}},
+		{"rgba(0,0,0,25%)", color.RGBA{0, 0, 0, 64}},
+		{"rgb(35,47,62)", color.RGBA{35, 47, 62, 255}},
+		{"#FF9900", color.RGBA{255, 153, 0, 255}},
+		{"#ff990080", color.RGBA{255, 153, 0, 128}},
+		{"#f90", color.RGBA{255, 153, 0, 255}},
+		{"Orange", color.RGBA{255, 165, 0, 255}},
+		{"transparent", color.RGBA{0, 0, 0, 0}},
+		{"hsl(120,100%,25%)", color.RGBA{0, 128, 0, 255}},
+		{"hsla(0, 100%, 50%, 128)", color.RGBA{255, 0, 0, 128}},
+	} {
+		c, err := stringToColor(tt.input)
+		if err != nil || c != tt.expected {
+			t.Errorf("%s: expected %v, got %v (%v)", tt.input, tt.expected, c, err)
+		}
+	}
+
+	for _, input := range []string{"", "rgba(0,0,0)", "rgb(256,0,0)", "#12345", "#gggggg", "hsl(0,50,50)", "not-a-color", "$orange"} {
+		if _, err := stringToColor(input); err == nil {
+			t.Errorf("%q: expected an error", input)
+		}
+	}
+}
+
+func TestResolveColors(t *testing.T) {
+	ds := definition.DefinitionStructure{
+		Colors: map[string]string{"aws-orange": "#FF9900", "border": "$aws-orange"},
+		Definitions: map[string]*definition.Definition{
+			"AWS::EC2::VPC": {Border: &definition.DefinitionBorder{Color: "$border"}},
+		},
+	}
+	iconFill := "$squid-ink"
+	template := TemplateStruct{Diagram: Diagram{
+		Colors: map[string]string{"squid-ink": "rgb(35,47,62)", "aws-orange": "#EC7211"},
+		Resources: map[string]Resource{
+			"VPC": {Type: "AWS::EC2::VPC", BorderColor: "$aws-orange", IconFill: &ResourceIconFill{Color: &iconFill}},
+		},
+		Links: []Link{{Source: "A", Target: "B", LineColor: "$squid-ink"}},
+	}}
+	if err := resolveColors(&template, &ds); err != nil {
+		t.Fatalf("resolveColors failed: %v", err)
+	}
+	// The template overrides the palette of the definitions, even through references
+	if c := ds.Definitions["AWS::EC2::VPC"].Border.Color; c != "#EC7211" {
+		t.Errorf("expected the definition border to be #EC7211, got %s", c)
+	}
+	if c := template.Resources["VPC"].BorderColor; c != "#EC7211" {
+		t.Errorf("expected the resource border to be #EC7211, got %s", c)
+	}
+	if c := *template.Resources["VPC"].IconFill.Color; c != "rgb(35,47,62)" {
+		t.Errorf("expected the icon fill to be rgb(35,47,62), got %s", c)
+	}
+	if c := template.Links[0].LineColor; c != "rgb(35,47,62)" {
+		t.Errorf("expected the line color to be rgb(35,47,62), got %s", c)
+	}
+
+	for _, tt := range []struct {
+		template TemplateStruct
+		field    string
+	}{
+		{TemplateStruct{Diagram: Diagram{Resources: map[string]Resource{"VPC": {Fill: &Fill{Type: "linear-gradient", From: "white", To: "$missing"}}}}}, "Resources.VPC.Fill.To"},
+		{TemplateStruct{Diagram: Diagram{Resources: map[string]Resource{"VPC": {TitleColor: "rgba(0,0,0)"}}}}, "Resources.VPC.TitleColor"},
+		{TemplateStruct{Diagram: Diagram{Links: []Link{{Source: "A", Target: "B", LineColor: "#12"}}}}, "Links[0] (A -> B).LineColor"},
+		{TemplateStruct{Diagram: Diagram{Colors: map[string]string{"a": "$b", "b": "$a"}}}, "Colors.a"},
+	} {
+		err := resolveColors(&tt.template, &definition.DefinitionStructure{})
+		if err == nil || !strings.HasPrefix(err.Error(), tt.field+":") {
+			t.Errorf("expected an error on %s, got %v", tt.field, err)
+		}
+	}
+
+	// The colors of the definitions are checked when a resource uses them as its type or its preset
+	for _, tt := range []struct {
+		def   *definition.Definition
+		field string
+	}{
+		{&definition.Definition{Border: &definition.DefinitionBorder{Color: "squid"}}, "Definitions.AWS::EC2::VPC.Border.Color"},
+		{&definition.Definition{Label: &definition.DefinitionLabel{Color: "$missing"}}, "Definitions.AWS::EC2::VPC.Label.Color"},
+		{&definition.Definition{Fill: &definition.DefinitionFill{Type: "linear-gradient", From: "white", To: "#12"}}, "Definitions.AWS::EC2::VPC.Fill.To"},
+	} {
+		for _, resource := range []Resource{
+			{Type: "AWS::EC2::VPC"},
+			{Type: "AWS::Diagram::Resource", Preset: "AWS::EC2::VPC"},
+		} {
+			ds := definition.DefinitionStructure{Definitions: map[string]*definition.Definition{"AWS::EC2::VPC": tt.def}}
+			template := TemplateStruct{Diagram: Diagram{Resources: map[string]Resource{"VPC": resource}}}
+			err := resolveColors(&template, &ds)
+			if err == nil || !strings.HasPrefix(err.Error(), tt.field+":") {
+				t.Errorf("%+v: expected an error on %s, got %v", resource, tt.field, err)
+			}
+		}
+
+		// A definition that no resource uses doesn't fail the diagram
+		ds := definition.DefinitionStructure{Definitions: map[string]*definition.Definition{"AWS::EC2::VPC": tt.def}}
+		template := TemplateStruct{Diagram: Diagram{Resources: map[string]Resource{"Box": {Type: "AWS::Diagram::Resource"}}}}
+		if err := resolveColors(&template, &ds); err != nil {
+			t.Errorf("expected no error on the unused %s, got %v", tt.field, err)
+		}
+	}
+}
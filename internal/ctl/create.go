@@ -11,6 +11,7 @@ import (
 	"image/png"
 	"math"
 	"os"
+	"sort"
 	"strings"
 
 	"github.com/awslabs/diagram-as-code/internal/cache"
@@ -21,15 +22,6 @@ import (
 	"golang.org/x/image/draw"
 )
 
-func stringToColor(c string) (color.RGBA, error) {
-	var r, g, b, a uint8
-	_, err := fmt.Sscanf(c, "rgba(%d,%d,%d,%d)", &r, &g, &b, &a)
-	if err != nil {
-		return color.RGBA{}, fmt.Errorf("failed to parse color string '%s': %w", c, err)
-	}
-	return color.RGBA{r, g, b, a}, nil
-}
-
 // OverwriteMode defines how to handle existing output files
 type OverwriteMode int
 
@@ -95,6 +87,10 @@ type Diagram struct {
 	DefinitionFiles []DefinitionFile    `yaml:"DefinitionFiles"`
 	Resources       map[string]Resource `yaml:"Resources"`
 	Links           []Link              `yaml:"Links"`
+	LineJump        string              `yaml:"LineJump"`
+	Colors          map[string]string   `yaml:"Colors"`
+	Styles          map[string]Style    `yaml:"Styles"`
+	Theme           string              `yaml:"Theme"`
 }
 
 type DefinitionFile struct {
@@ -105,25 +101,53 @@ type DefinitionFile struct {
 }
 
 type Resource struct {
-	Type           string            `yaml:"Type"`
-	Icon           string            `yaml:"Icon"`
-	IconFill       *ResourceIconFill `yaml:"IconFill"`
-	Direction      string            `yaml:"Direction"`
-	Preset         string            `yaml:"Preset"`
-	Align          string            `yaml:"Align"`
-	HeaderAlign    string            `yaml:"HeaderAlign"`
-	FillColor      string            `yaml:"FillColor"`
-	Title          string            `yaml:"Title"`
-	TitleColor     string            `yaml:"TitleColor"`
-	Font           string            `yaml:"Font"`
-	Children       []string          `yaml:"Children"`
-	BorderColor    string            `yaml:"BorderColor"`
-	BorderChildren []BorderChild     `yaml:"BorderChildren"`
-	Options        *ResourceOptions  `yaml:"Options"`
+	Type              string            `yaml:"Type"`
+	Icon              string            `yaml:"Icon"`
+	IconFill          *ResourceIconFill `yaml:"IconFill"`
+	Direction         string            `yaml:"Direction"`
+	Preset            string            `yaml:"Preset"`
+	Class             string            `yaml:"Class"`
+	Align             string            `yaml:"Align"`
+	HeaderAlign       string            `yaml:"HeaderAlign"`
+	FillColor         string            `yaml:"FillColor"`
+	Title             string            `yaml:"Title"`
+	TitleColor        string            `yaml:"TitleColor"`
+	Font              string            `yaml:"Font"`
+	Children          []string          `yaml:"Children"`
+	BorderColor       string            `yaml:"BorderColor"`
+	BorderType        string            `yaml:"BorderType"`
+	BorderWidth       *int              `yaml:"BorderWidth"`
+	BorderDashPattern []float64         `yaml:"BorderDashPattern"`
+	CornerRadius      *int              `yaml:"CornerRadius"`
+	Shadow            *Shadow           `yaml:"Shadow"`
+	Fill              *Fill             `yaml:"Fill"`
+	BorderChildren    []BorderChild     `yaml:"BorderChildren"`
+	Columns           int               `yaml:"Columns"`
+	Rows              int               `yaml:"Rows"`
+	ColumnSpan        int               `yaml:"ColumnSpan"`
+	RowSpan           int               `yaml:"RowSpan"`
+	RowAlign          string            `yaml:"RowAlign"`
+	ColumnAlign       string            `yaml:"ColumnAlign"`
+	Tiers             []string          `yaml:"Tiers"`
+	AvailabilityZone  string            `yaml:"AvailabilityZone"`
+	Tier              string            `yaml:"Tier"`
+	Members           []string          `yaml:"Members"`
+	Width             int               `yaml:"Width"`
+	Height            int               `yaml:"Height"`
+	MinWidth          int               `yaml:"MinWidth"`
+	Wrap              int               `yaml:"Wrap"`
+	MaxWidth          int               `yaml:"MaxWidth"`
+	Margin            *types.Spacing    `yaml:"Margin"`
+	Padding           *types.Spacing    `yaml:"Padding"`
+	Offset            *Point            `yaml:"Offset"`
+	Pin               *Point            `yaml:"Pin"`
+	Options           *ResourceOptions  `yaml:"Options"`
+	Ports             map[string]Port   `yaml:"Ports"`
 }
 
 type ResourceOptions struct {
 	GroupingOffset *bool `yaml:"GroupingOffset"`
+	EqualSize      *bool `yaml:"EqualSize"`
 }
 
 type ResourceIconFill struct {
@@ -131,11 +155,38 @@ type ResourceIconFill struct {
 	Color *string `yaml:"Color"`
 }
 
+// Fill is a solid color or a linear gradient filling a group
+type Fill struct {
+	Type  string  `yaml:"Type"` // solid(default) / linear-gradient
+	Color string  `yaml:"Color"`
+	From  string  `yaml:"From"`
+	To    string  `yaml:"To"`
+	Angle float64 `yaml:"Angle"`
+}
+
+type Shadow struct {
+	Offset Point  `yaml:"Offset"`
+	Blur   int    `yaml:"Blur"`
+	Color  string `yaml:"Color"`
+}
+
+// Port is a named point on a side of a resource, At being the fraction of the side from its north
+// or west end (default: 0.5)
+type Port struct {
+	Side string   `yaml:"Side"`
+	At   *float64 `yaml:"At"`
+}
+
 type BorderChild struct {
 	Position string `yaml:"Position"`
 	Resource string `yaml:"Resource"`
 }
 
+type Point struct {
+	X int `yaml:"X"`
+	Y int `yaml:"Y"`
+}
+
 type Link struct {
 	Source          string          `yaml:"Source"`
 	SourcePosition  string          `yaml:"SourcePosition"`
@@ -144,17 +195,31 @@ type Link struct {
 	TargetPosition  string          `yaml:"TargetPosition"`
 	TargetArrowHead types.ArrowHead `yaml:"TargetArrowHead"`
 	Type            string          `yaml:"Type"`
+	Class           string          `yaml:"Class"`
 	LineWidth       int             `yaml:"LineWidth"`
 	LineColor       string          `yaml:"LineColor"`
 	LineStyle       string          `yaml:"LineStyle"`
+	DashPattern     []float64       `yaml:"DashPattern"`
+	AvoidHeaders    bool            `yaml:"AvoidHeaders"`
+	LineJump        string          `yaml:"LineJump"`
+	Bundle          string          `yaml:"Bundle"`
+	Waypoints       []Point         `yaml:"Waypoints"`
+	Via             []LinkVia       `yaml:"Via"`
 	Labels          LinkLabels      `yaml:"Labels"`
 }
 
+type LinkVia struct {
+	Resource string `yaml:"Resource"`
+	Position string `yaml:"Position"`
+	Offset   int    `yaml:"Offset"`
+}
+
 type LinkLabels struct {
 	SourceRight *LinkLabel `yaml:"SourceRight"`
 	SourceLeft  *LinkLabel `yaml:"SourceLeft"`
 	TargetRight *LinkLabel `yaml:"TargetRight"`
 	TargetLeft  *LinkLabel `yaml:"TargetLeft"`
+	Center      *LinkLabel `yaml:"Center"`
 }
 
 type LinkLabel struct {
@@ -170,6 +235,7 @@ type CreateOptions struct {
 	AllowUntrustedDefinitions bool
 	OverwriteMode             OverwriteMode
 	OverrideFont              string
+	Theme                     string // overrides the Theme field of the DAC file
 	Width                     int
 	Height                    int
 }
@@ -193,19 +259,8 @@ func createDiagram(resources map[string]*types.Resource, outputfile *string, opt
 	if !exists {
 		return fmt.Errorf("Canvas resource not found")
 	}
-	err := canvas.Scale(nil, nil)
-	if err != nil {
-		return fmt.Errorf("error scaling diagram: %w", err)
-	}
-	if err := canvas.ZeroAdjust(); err != nil {
-		return fmt.Errorf("error adjusting diagram: %w", err)
-	}
-
-	// Resolve auto-positions after layout is complete
-	for _, resource := range resources {
-		for _, link := range resource.GetLinks() {
-			link.ResolveAutoPositions()
-		}
+	if err := layoutDiagram(canvas, resources); err != nil {
+		return err
 	}
 
 	img, err := canvas.Draw(nil, nil)
@@ -236,6 +291,31 @@ func createDiagram(resources map[string]*types.Resource, outputfile *string, opt
 	return nil
 }
 
+// layoutDiagram places every resource from the canvas and resolves the auto positions of the links
+func layoutDiagram(canvas *types.Resource, resources map[string]*types.Resource) error {
+	err := canvas.Scale(nil, nil)
+	if err != nil {
+		return fmt.Errorf("error scaling diagram: %w", err)
+	}
+	if err := canvas.ZeroAdjust(); err != nil {
+		return fmt.Errorf("error adjusting diagram: %w", err)
+	}
+	if err := canvas.ScaleOverlays(); err != nil {
+		return fmt.Errorf("error scaling overlays: %w", err)
+	}
+
+	// Resolve auto-positions after layout is complete
+	router := types.NewRouter(canvas)
+	for _, resource := range resources {
+		for _, link := range resource.GetLinks() {
+			link.ResolveAutoPositions()
+			link.SetObstacles(canvas.GetOverlays())
+			link.SetRouter(router)
+		}
+	}
+	return nil
+}
+
 // resizeImage resizes the image while maintaining aspect ratio
 func resizeImage(src *image.RGBA, width, height int) *image.RGBA {
 	// Get original dimensions
@@ -324,6 +404,10 @@ func loadDefinitionFiles(template *TemplateStruct, ds *definition.DefinitionStru
 		case "Embed":
 			log.Info("Read embedded definitions")
 			maps.Copy(ds.Definitions, v.Embed.Definitions)
+			if ds.Colors == nil {
+				ds.Colors = map[string]string{}
+			}
+			maps.Copy(ds.Colors, v.Embed.Colors)
 		}
 	}
 	return nil
@@ -332,6 +416,10 @@ func loadDefinitionFiles(template *TemplateStruct, ds *definition.DefinitionStru
 
 func loadResources(template *TemplateStruct, ds definition.DefinitionStructure, resources map[string]*types.Resource) error {
 
+	theme, err := template.theme()
+	if err != nil {
+		return err
+	}
 	resources["Canvas"] = new(types.Resource).Init()
 
 	for k, v := range template.Resources {
@@ -347,13 +435,25 @@ func loadResources(template *TemplateStruct, ds definition.DefinitionStructure,
 				return fmt.Errorf("Canvas resource %s not found in resources map", k)
 			}
 			resource.SetBorderColor(color.RGBA{0, 0, 0, 0})
-			resource.SetFillColor(color.RGBA{255, 255, 255, 255})
+			resource.SetFillColor(theme.Background)
 		case "AWS::Diagram::Resource":
 			resources[k] = new(types.Resource).Init()
+			setThemeDefaults(resources[k], theme, len(v.Children) != 0)
 		case "AWS::Diagram::VerticalStack":
 			resources[k] = new(types.VerticalStack).Init()
 		case "AWS::Diagram::HorizontalStack":
 			resources[k] = new(types.HorizontalStack).Init()
+		case "AWS::Diagram::Grid":
+			resources[k] = new(types.Grid).Init()
+		case "AWS::Diagram::Overlay":
+			resources[k] = new(types.Overlay).Init()
+			setThemeDefaults(resources[k], theme, false)
+		case "AWS::Diagram::Spacer":
+			resources[k] = new(types.Spacer).Init()
+			setThemeDefaults(resources[k], theme, false)
+		case "AWS::Diagram::Divider":
+			resources[k] = new(types.Divider).Init()
+			setThemeDefaults(resources[k], theme, false)
 		default:
 			def, ok := ds.Definitions[v.Type]
 			if !ok {
@@ -376,34 +476,25 @@ func loadResources(template *TemplateStruct, ds definition.DefinitionStructure,
 			case "Group":
 				resources[k] = new(types.Resource).Init()
 			}
+			if resource, exists := resources[k]; exists {
+				setThemeDefaults(resource, theme, len(v.Children) != 0)
+			}
 			if fill := def.Fill; fill != nil {
-				fillColor, err := stringToColor(fill.Color)
-				if err != nil {
-					return fmt.Errorf("failed to parse fill color for resource %s: %w", k, err)
-				}
 				resource, exists := resources[k]
 				if !exists {
 					return fmt.Errorf("resource %s not found for fill color", k)
 				}
-				resource.SetFillColor(fillColor)
+				if err := setFill(resource, fill); err != nil {
+					return fmt.Errorf("failed to set fill for resource %s: %w", k, err)
+				}
 			}
 			if border := def.Border; border != nil {
-				borderColor, err := stringToColor(border.Color)
-				if err != nil {
-					return fmt.Errorf("failed to parse border color for resource %s: %w", k, err)
-				}
 				resource, exists := resources[k]
 				if !exists {
 					return fmt.Errorf("resource %s not found when setting border", k)
 				}
-				resource.SetBorderColor(borderColor)
-				switch border.Type {
-				case "straight":
-					resource.SetBorderType(types.BORDER_TYPE_STRAIGHT)
-				case "dashed":
-					resource.SetBorderType(types.BORDER_TYPE_DASHED)
-				default:
-					resource.SetBorderType(types.BORDER_TYPE_STRAIGHT)
+				if err := setBorder(resource, border); err != nil {
+					return fmt.Errorf("failed to set border for resource %s: %w", k, err)
 				}
 			}
 			if label := def.Label; label != nil {
@@ -440,6 +531,17 @@ func loadResources(template *TemplateStruct, ds definition.DefinitionStructure,
 				}
 				resource.SetHeaderAlign(headerAlign)
 			}
+			if len(def.Ports) != 0 {
+				resource, exists := resources[k]
+				if !exists {
+					return fmt.Errorf("resource %s not found when setting ports", k)
+				}
+				for name, port := range def.Ports {
+					if err := setPort(resource, name, port.Side, port.At); err != nil {
+						return fmt.Errorf("failed to set port of resource %s: %w", k, err)
+					}
+				}
+			}
 			if icon := def.Icon; icon != nil {
 				if def.CacheFilePath == "" {
 					break
@@ -474,25 +576,13 @@ func loadResources(template *TemplateStruct, ds definition.DefinitionStructure,
 					return fmt.Errorf("resource %s not found for preset configuration", k)
 				}
 				if fill := def.Fill; fill != nil {
-					fillColor, err := stringToColor(fill.Color)
-					if err != nil {
-						return fmt.Errorf("failed to parse fill color for resource %s: %w", k, err)
+					if err := setFill(resource, fill); err != nil {
+						return fmt.Errorf("failed to set fill for resource %s: %w", k, err)
 					}
-					resource.SetFillColor(fillColor)
 				}
 				if border := def.Border; border != nil {
-					borderColor, err := stringToColor(border.Color)
-					if err != nil {
-						return fmt.Errorf("failed to parse border color for resource %s: %w", k, err)
-					}
-					resource.SetBorderColor(borderColor)
-					switch border.Type {
-					case "straight":
-						resource.SetBorderType(types.BORDER_TYPE_STRAIGHT)
-					case "dashed":
-						resource.SetBorderType(types.BORDER_TYPE_DASHED)
-					default:
-						resource.SetBorderType(types.BORDER_TYPE_STRAIGHT)
+					if err := setBorder(resource, border); err != nil {
+						return fmt.Errorf("failed to set border for resource %s: %w", k, err)
 					}
 				}
 				if label := def.Label; label != nil {
@@ -513,6 +603,11 @@ func loadResources(template *TemplateStruct, ds definition.DefinitionStructure,
 				if headerAlign := def.HeaderAlign; headerAlign != "" {
 					resource.SetHeaderAlign(headerAlign)
 				}
+				for name, port := range def.Ports {
+					if err := setPort(resource, name, port.Side, port.At); err != nil {
+						return fmt.Errorf("failed to set port of resource %s: %w", k, err)
+					}
+				}
 				if icon := def.Icon; icon != nil {
 					if def.CacheFilePath != "" {
 						err := resource.LoadIcon(def.CacheFilePath)
@@ -580,6 +675,17 @@ func loadResources(template *TemplateStruct, ds definition.DefinitionStructure,
 			}
 			resource.SetHeaderAlign(v.HeaderAlign)
 		}
+		if len(v.Ports) != 0 {
+			resource, exists := resources[k]
+			if !exists {
+				return fmt.Errorf("resource %s not found for ports", k)
+			}
+			for name, port := range v.Ports {
+				if err := setPort(resource, name, port.Side, port.At); err != nil {
+					return fmt.Errorf("failed to set port of resource %s: %w", k, err)
+				}
+			}
+		}
 		if v.Font != "" {
 			resource, exists := resources[k]
 			if !exists {
@@ -623,6 +729,135 @@ func loadResources(template *TemplateStruct, ds definition.DefinitionStructure,
 			}
 			resource.SetBorderColor(borderColor)
 		}
+		if v.BorderType != "" || v.BorderWidth != nil || len(v.BorderDashPattern) != 0 {
+			resource, exists := resources[k]
+			if !exists {
+				return fmt.Errorf("resource %s not found for border", k)
+			}
+			if err := setBorderStyle(resource, v.BorderType, v.BorderWidth, v.BorderDashPattern); err != nil {
+				return fmt.Errorf("failed to set border for resource %s: %w", k, err)
+			}
+		}
+		if v.CornerRadius != nil {
+			if *v.CornerRadius < 0 {
+				return fmt.Errorf("CornerRadius must not be negative on resource %s", k)
+			}
+			resource, exists := resources[k]
+			if !exists {
+				return fmt.Errorf("resource %s not found for corner radius", k)
+			}
+			resource.SetCornerRadius(*v.CornerRadius)
+		}
+		if v.Fill != nil {
+			resource, exists := resources[k]
+			if !exists {
+				return fmt.Errorf("resource %s not found for fill", k)
+			}
+			fill := definition.DefinitionFill(*v.Fill)
+			if err := setFill(resource, &fill); err != nil {
+				return fmt.Errorf("failed to set fill for resource %s: %w", k, err)
+			}
+		}
+		if v.Shadow != nil {
+			resource, exists := resources[k]
+			if !exists {
+				return fmt.Errorf("resource %s not found for shadow", k)
+			}
+			shadow := definition.DefinitionShadow{
+				Offset: definition.DefinitionOffset(v.Shadow.Offset),
+				Blur:   v.Shadow.Blur,
+				Color:  v.Shadow.Color,
+			}
+			if err := setShadow(resource, &shadow); err != nil {
+				return fmt.Errorf("failed to set shadow for resource %s: %w", k, err)
+			}
+		}
+		if v.Columns != 0 || v.Rows != 0 {
+			if v.Columns < 0 || v.Rows < 0 {
+				return fmt.Errorf("Columns and Rows must not be negative on resource %s", k)
+			}
+			resource, exists := resources[k]
+			if !exists {
+				return fmt.Errorf("resource %s not found for grid size", k)
+			}
+			resource.SetGrid(v.Columns, v.Rows)
+		}
+		if v.ColumnSpan != 0 || v.RowSpan != 0 {
+			if v.ColumnSpan < 0 || v.RowSpan < 0 {
+				return fmt.Errorf("ColumnSpan and RowSpan must not be negative on resource %s", k)
+			}
+			resource, exists := resources[k]
+			if !exists {
+				return fmt.Errorf("resource %s not found for grid span", k)
+			}
+			resource.SetGridSpan(max(v.ColumnSpan, 1), max(v.RowSpan, 1))
+		}
+		if v.RowAlign != "" || v.ColumnAlign != "" {
+			resource, exists := resources[k]
+			if !exists {
+				return fmt.Errorf("resource %s not found for grid align", k)
+			}
+			resource.SetGridAlign(v.RowAlign, v.ColumnAlign)
+		}
+		if v.Width != 0 || v.Height != 0 || v.MinWidth != 0 {
+			if v.Width < 0 || v.Height < 0 || v.MinWidth < 0 {
+				return fmt.Errorf("Width, Height and MinWidth must not be negative on resource %s", k)
+			}
+			resource, exists := resources[k]
+			if !exists {
+				return fmt.Errorf("resource %s not found for size", k)
+			}
+			resource.SetSize(v.Width, v.Height)
+			resource.SetMinWidth(v.MinWidth)
+		}
+		if v.Wrap != 0 || v.MaxWidth != 0 {
+			if v.Wrap < 0 || v.MaxWidth < 0 {
+				return fmt.Errorf("Wrap and MaxWidth must not be negative on resource %s", k)
+			}
+			resource, exists := resources[k]
+			if !exists {
+				return fmt.Errorf("resource %s not found for wrap", k)
+			}
+			resource.SetWrap(v.Wrap, v.MaxWidth)
+		}
+		if v.Margin != nil {
+			resource, exists := resources[k]
+			if !exists {
+				return fmt.Errorf("resource %s not found for margin", k)
+			}
+			resource.SetMarginOverride(*v.Margin)
+		}
+		if v.Padding != nil {
+			resource, exists := resources[k]
+			if !exists {
+				return fmt.Errorf("resource %s not found for padding", k)
+			}
+			resource.SetPaddingOverride(*v.Padding)
+		}
+		if v.Offset != nil {
+			resource, exists := resources[k]
+			if !exists {
+				return fmt.Errorf("resource %s not found for offset", k)
+			}
+			resource.SetOffset(image.Point{v.Offset.X, v.Offset.Y})
+		}
+		if v.Pin != nil {
+			if v.Pin.X < 0 || v.Pin.Y < 0 {
+				return fmt.Errorf("Pin must not be negative on resource %s", k)
+			}
+			resource, exists := resources[k]
+			if !exists {
+				return fmt.Errorf("resource %s not found for pin", k)
+			}
+			resource.SetPin(image.Point{v.Pin.X, v.Pin.Y})
+		}
+		if len(v.Tiers) != 0 {
+			resource, exists := resources[k]
+			if !exists {
+				return fmt.Errorf("resource %s not found for tiers", k)
+			}
+			resource.SetTiers(v.Tiers)
+		}
 
 		// Process Options
 		if v.Options != nil {
@@ -633,6 +868,9 @@ func loadResources(template *TemplateStruct, ds definition.DefinitionStructure,
 			if v.Options.GroupingOffset != nil {
 				resource.SetGroupingOffset(*v.Options.GroupingOffset)
 			}
+			if v.Options.EqualSize != nil {
+				resource.SetEqualSize(*v.Options.EqualSize)
+			}
 		}
 	}
 
@@ -649,6 +887,7 @@ func fallbackToServiceIcon(inputType string) string {
 
 func associateChildren(template *TemplateStruct, resources map[string]*types.Resource) error {
 
+	overlays := []string{}
 	for logicalId, v := range template.Resources {
 		resource, ok := resources[logicalId]
 		if !ok {
@@ -665,6 +904,34 @@ func associateChildren(template *TemplateStruct, resources map[string]*types.Res
 				log.Warnf("Child `%s` was not found, ignoring it.", child)
 			}
 		}
+		if v.AvailabilityZone != "" || v.Tier != "" {
+			var availabilityZone *types.Resource
+			if v.AvailabilityZone != "" {
+				availabilityZone, ok = resources[v.AvailabilityZone]
+				if !ok {
+					log.Warnf("AvailabilityZone `%s` was not found, ignoring it.", v.AvailabilityZone)
+				}
+			}
+			log.Infof("Set matrix cell(%s, %s) on %s", v.AvailabilityZone, v.Tier, logicalId)
+			resource.SetMatrixCell(availabilityZone, v.Tier)
+		}
+		if len(v.Members) != 0 {
+			if len(v.Children) != 0 {
+				return fmt.Errorf("resource %s cannot have both Children and Members", logicalId)
+			}
+			for _, member := range v.Members {
+				memberResource, ok := resources[member]
+				if !ok {
+					log.Warnf("Member `%s` was not found, ignoring it.", member)
+					continue
+				}
+				log.Infof("Add member(%s) on %s", member, logicalId)
+				if err := resource.AddMember(memberResource); err != nil {
+					return fmt.Errorf("failed to add member %s to %s: %w", member, logicalId, err)
+				}
+			}
+			overlays = append(overlays, logicalId)
+		}
 		for _, borderChild := range v.BorderChildren {
 			borderChildResource, ok := resources[borderChild.Resource]
 			if !ok {
@@ -686,15 +953,215 @@ func associateChildren(template *TemplateStruct, resources map[string]*types.Res
 			}
 		}
 	}
+
+	// Overlays are drawn in the order of their logical IDs
+	sort.Strings(overlays)
+	for _, logicalId := range overlays {
+		log.Infof("Add overlay(%s) on Canvas", logicalId)
+		if err := resources["Canvas"].AddOverlay(resources[logicalId]); err != nil {
+			return fmt.Errorf("failed to add overlay %s: %w", logicalId, err)
+		}
+	}
+	return nil
+}
+
+// setBorder applies the border of a definition to the resource
+func setBorder(resource *types.Resource, border *definition.DefinitionBorder) error {
+	borderColor, err := stringToColor(border.Color)
+	if err != nil {
+		return fmt.Errorf("failed to parse border color: %w", err)
+	}
+	resource.SetBorderColor(borderColor)
+	borderType := border.Type
+	switch borderType {
+	case "straight", "dashed", "dotted", "dash-dot":
+	default:
+		// Definitions fall back to a straight border
+		borderType = "straight"
+	}
+	var width *int
+	if border.Width != 0 {
+		width = &border.Width
+	}
+	if border.CornerRadius < 0 {
+		return fmt.Errorf("corner radius must not be negative: %d", border.CornerRadius)
+	}
+	if border.CornerRadius != 0 {
+		resource.SetCornerRadius(border.CornerRadius)
+	}
+	if border.Shadow != nil {
+		if err := setShadow(resource, border.Shadow); err != nil {
+			return err
+		}
+	}
+	return setBorderStyle(resource, borderType, width, border.DashPattern)
+}
+
+// setFill fills the resource with a solid color or a linear gradient
+func setFill(resource *types.Resource, fill *definition.DefinitionFill) error {
+	switch fill.Type {
+	case "", "solid":
+		fillColor, err := stringToColor(fill.Color)
+		if err != nil {
+			return fmt.Errorf("failed to parse fill color: %w", err)
+		}
+		resource.SetFillColor(fillColor)
+	case "linear-gradient":
+		from, err := stringToColor(fill.From)
+		if err != nil {
+			return fmt.Errorf("failed to parse gradient From color: %w", err)
+		}
+		to, err := stringToColor(fill.To)
+		if err != nil {
+			return fmt.Errorf("failed to parse gradient To color: %w", err)
+		}
+		resource.SetGradientFill(&types.Gradient{From: from, To: to, Angle: fill.Angle})
+	default:
+		return fmt.Errorf("unknown fill type: %s, supported fill types are solid, linear-gradient", fill.Type)
+	}
+	return nil
+}
+
+// setShadow draws a shadow under the resource, a soft black one unless a color is given
+func setShadow(resource *types.Resource, shadow *definition.DefinitionShadow) error {
+	if shadow.Blur < 0 {
+		return fmt.Errorf("shadow blur must not be negative: %d", shadow.Blur)
+	}
+	shadowColor := color.RGBA{0, 0, 0, 64}
+	if shadow.Color != "" {
+		c, err := stringToColor(shadow.Color)
+		if err != nil {
+			return fmt.Errorf("failed to parse shadow color: %w", err)
+		}
+		shadowColor = c
+	}
+	resource.SetShadow(&types.Shadow{
+		Offset: image.Point{shadow.Offset.X, shadow.Offset.Y},
+		Blur:   shadow.Blur,
+		Color:  shadowColor,
+	})
+	return nil
+}
+
+// setBorderStyle sets the type, the width and the dash pattern of the border when they are given
+func setBorderStyle(resource *types.Resource, borderType string, width *int, dashPattern []float64) error {
+	switch borderType {
+	case "":
+	case "straight":
+		resource.SetBorderType(types.BORDER_TYPE_STRAIGHT)
+	case "dashed":
+		resource.SetBorderType(types.BORDER_TYPE_DASHED)
+	case "dotted":
+		resource.SetBorderType(types.BORDER_TYPE_DOTTED)
+	case "dash-dot":
+		resource.SetBorderType(types.BORDER_TYPE_DASH_DOT)
+	default:
+		return fmt.Errorf("unknown border type: %s, supported border types are straight, dashed, dotted, dash-dot", borderType)
+	}
+	if width != nil {
+		if *width < 0 {
+			return fmt.Errorf("border width must not be negative: %d", *width)
+		}
+		resource.SetBorderWidth(*width)
+	}
+	if len(dashPattern) != 0 {
+		if err := validateDashPattern(dashPattern); err != nil {
+			return err
+		}
+		resource.SetBorderDashPattern(dashPattern)
+	}
+	return nil
+}
+
+// validateArrowHead checks the type of an arrow head, which draws no arrow head when it is empty
+func validateArrowHead(arrowHead types.ArrowHead) error {
+	switch arrowHead.Type {
+	case "", "Default", "Open", "Diamond", "OpenDiamond", "Circle", "OpenCircle", "Bar", "Many", "OneOrMany", "ZeroOrMany", "Double":
+		return nil
+	}
+	return fmt.Errorf("unknown arrow head type: %s, supported arrow head types are Default, Open, Diamond, OpenDiamond, Circle, OpenCircle, Bar, Many, OneOrMany, ZeroOrMany, Double", arrowHead.Type)
+}
+
+// validateDashPattern checks that a pattern alternates dashes and gaps of positive lengths
+func validateDashPattern(pattern []float64) error {
+	if len(pattern)%2 != 0 {
+		return fmt.Errorf("dash pattern %v must have an even number of lengths, dashes and gaps", pattern)
+	}
+	for _, length := range pattern {
+		if length <= 0 {
+			return fmt.Errorf("dash pattern %v must have positive lengths", pattern)
+		}
+	}
 	return nil
 }
 
+// setPort declares a named port on the resource, in the middle of its side by default
+func setPort(resource *types.Resource, name, side string, at *float64) error {
+	fraction := 0.5
+	if at != nil {
+		fraction = *at
+	}
+	anchor, err := types.NewAnchor(side, fraction)
+	if err != nil {
+		return fmt.Errorf("port %s: %w", name, err)
+	}
+	resource.SetPort(name, anchor)
+	return nil
+}
+
+// convertPosition converts the position of an end of a link on the resource. Besides windrose
+// positions, it accepts fractional anchors such as E@0.3 and the names of the ports of the
+// resource, which are returned as an anchor.
+func convertPosition(resource *types.Resource, position string) (types.Windrose, *types.Anchor, error) {
+	if strings.Contains(position, "@") {
+		anchor, err := types.ConvertAnchor(position)
+		if err != nil {
+			return 0, nil, err
+		}
+		return anchor.Side, &anchor, nil
+	}
+	windrose, err := types.ConvertWindrose(position)
+	if err == nil {
+		return windrose, nil, nil
+	}
+	if anchor, ok := resource.GetPort(position); ok {
+		return anchor.Side, &anchor, nil
+	}
+	return 0, nil, fmt.Errorf("%w, a fractional anchor such as E@0.3, or a port of the resource", err)
+}
+
+// convertWaypoints returns the points a link passes through, either Waypoints on the canvas or
+// Via points relative to resources
+func convertWaypoints(v Link, resources map[string]*types.Resource) ([]types.Waypoint, error) {
+	if len(v.Waypoints) != 0 && len(v.Via) != 0 {
+		return nil, fmt.Errorf("link(%s-%s) cannot have both Waypoints and Via", v.Source, v.Target)
+	}
+	waypoints := []types.Waypoint{}
+	for _, p := range v.Waypoints {
+		waypoints = append(waypoints, types.Waypoint{Point: image.Point{p.X, p.Y}})
+	}
+	for _, via := range v.Via {
+		resource, ok := resources[via.Resource]
+		if !ok {
+			return nil, fmt.Errorf("Via resource %s of link(%s-%s) not found", via.Resource, v.Source, v.Target)
+		}
+		position, err := types.ConvertWindrose(via.Position)
+		if err != nil {
+			return nil, fmt.Errorf("failed to convert via windrose position: %w", err)
+		}
+		waypoints = append(waypoints, types.Waypoint{Resource: resource, Position: position, Offset: via.Offset})
+	}
+	return waypoints, nil
+}
+
 func convertLabel(label *LinkLabel) (*types.LinkLabel, error) {
 	r := &types.LinkLabel{}
 	if label.Type != nil {
 		switch *label.Type {
 		case "horizontal":
 			r.Type = types.LINK_LABEL_TYPE_HORIZONTAL
+		case "along-path":
+			r.Type = types.LINK_LABEL_TYPE_ALONG_PATH
 		default:
 			r.Type = types.LINK_LABEL_TYPE_HORIZONTAL
 		}
@@ -716,6 +1183,10 @@ func convertLabel(label *LinkLabel) (*types.LinkLabel, error) {
 }
 
 func loadLinks(template *TemplateStruct, resources map[string]*types.Resource) error {
+	theme, err := template.theme()
+	if err != nil {
+		return err
+	}
 
 	for _, v := range template.Links {
 		sourceResource, ok := resources[v.Source]
@@ -738,7 +1209,7 @@ func loadLinks(template *TemplateStruct, resources map[string]*types.Resource) e
 			lineWidth = 2
 		}
 
-		lineColor := color.RGBA{0, 0, 0, 255}
+		lineColor := theme.Line
 		if v.LineColor != "" {
 			var err error
 			lineColor, err = stringToColor(v.LineColor)
@@ -748,18 +1219,55 @@ func loadLinks(template *TemplateStruct, resources map[string]*types.Resource) e
 		}
 
 		// Convert positions (empty string and "auto" both become WINDROSE_AUTO)
-		sourcePosition, err := types.ConvertWindrose(v.SourcePosition)
+		sourcePosition, sourceAnchor, err := convertPosition(source, v.SourcePosition)
 		if err != nil {
 			return fmt.Errorf("failed to convert source windrose position: %w", err)
 		}
-		targetPosition, err := types.ConvertWindrose(v.TargetPosition)
+		targetPosition, targetAnchor, err := convertPosition(target, v.TargetPosition)
 		if err != nil {
 			return fmt.Errorf("failed to convert target windrose position: %w", err)
 		}
 
+		if err := validateArrowHead(v.SourceArrowHead); err != nil {
+			return fmt.Errorf("link(%s-%s) SourceArrowHead: %w", v.Source, v.Target, err)
+		}
+		if err := validateArrowHead(v.TargetArrowHead); err != nil {
+			return fmt.Errorf("link(%s-%s) TargetArrowHead: %w", v.Source, v.Target, err)
+		}
 		link := new(types.Link).Init(source, sourcePosition, v.SourceArrowHead, target, targetPosition, v.TargetArrowHead, lineWidth, lineColor)
+		if sourceAnchor != nil {
+			link.SetSourceAnchor(*sourceAnchor)
+		}
+		if targetAnchor != nil {
+			link.SetTargetAnchor(*targetAnchor)
+		}
 		link.SetType(v.Type)
 		link.SetLineStyle(v.LineStyle)
+		if len(v.DashPattern) != 0 {
+			if err := validateDashPattern(v.DashPattern); err != nil {
+				return fmt.Errorf("link(%s-%s): %w", v.Source, v.Target, err)
+			}
+			link.SetDashPattern(v.DashPattern)
+		}
+		link.SetAvoidHeaders(v.AvoidHeaders)
+		link.SetBundle(v.Bundle)
+		lineJump := v.LineJump
+		if lineJump == "" {
+			lineJump = template.LineJump
+		}
+		switch lineJump {
+		case "", "none":
+			link.SetLineJump(types.LINE_JUMP_NONE)
+		case types.LINE_JUMP_ARC, types.LINE_JUMP_GAP:
+			link.SetLineJump(lineJump)
+		default:
+			return fmt.Errorf("unknown line jump: %s, supported line jumps are arc, gap, none", lineJump)
+		}
+		waypoints, err := convertWaypoints(v, resources)
+		if err != nil {
+			return err
+		}
+		link.SetWaypoints(waypoints)
 		if v.Labels.SourceRight != nil {
 			label, err := convertLabel(v.Labels.SourceRight)
 			if err != nil {
@@ -788,6 +1296,13 @@ func loadLinks(template *TemplateStruct, resources map[string]*types.Resource) e
 			}
 			link.Labels.TargetLeft = label
 		}
+		if v.Labels.Center != nil {
+			label, err := convertLabel(v.Labels.Center)
+			if err != nil {
+				return fmt.Errorf("failed to convert center label: %w", err)
+			}
+			link.Labels.Center = label
+		}
 		source.AddLink(link)
 		target.AddLink(link)
 	}
@@ -6,6 +6,7 @@ package ctl
 import (
 	"image"
 	"reflect"
+	"strings"
 	"testing"
 
 	"github.com/awslabs/diagram-as-code/internal/definition"
@@ -278,6 +279,247 @@ func TestLoadResourcesWithNoFallbackPossible(t *testing.T) {
 	}
 }
 
+func TestLoadResourcesWithGrid(t *testing.T) {
+	template := &TemplateStruct{
+		Diagram: Diagram{
+			Resources: map[string]Resource{
+				"Grid": {
+					Type:        "AWS::Diagram::Grid",
+					Columns:     3,
+					RowAlign:    "top",
+					ColumnAlign: "left",
+				},
+				"Cell": {
+					Type:       "AWS::Diagram::Resource",
+					ColumnSpan: 2,
+				},
+			},
+		},
+	}
+
+	actualResources := make(map[string]*types.Resource)
+	if err := loadResources(template, definition.DefinitionStructure{}, actualResources); err != nil {
+		t.Fatalf("loadResources failed: %v", err)
+	}
+
+	expectedGrid := new(types.Grid).Init()
+	expectedGrid.SetGrid(3, 0)
+	expectedGrid.SetGridAlign("top", "left")
+	if !reflect.DeepEqual(actualResources["Grid"], expectedGrid) {
+		t.Errorf("Grid deep comparison failed.\nExpected: %+v\nActual: %+v", expectedGrid, actualResources["Grid"])
+	}
+
+	expectedCell := new(types.Resource).Init()
+	expectedCell.SetGridSpan(2, 1)
+	if !reflect.DeepEqual(actualResources["Cell"], expectedCell) {
+		t.Errorf("Cell deep comparison failed.\nExpected: %+v\nActual: %+v", expectedCell, actualResources["Cell"])
+	}
+
+	template.Resources["Cell"] = Resource{Type: "AWS::Diagram::Resource", RowSpan: -1}
+	if err := loadResources(template, definition.DefinitionStructure{}, make(map[string]*types.Resource)); err == nil {
+		t.Error("expected error for negative RowSpan")
+	}
+}
+
+func TestLoadResourcesWithSize(t *testing.T) {
+	left := 40
+	template := &TemplateStruct{
+		Diagram: Diagram{
+			Resources: map[string]Resource{
+				"VPC": {
+					Type:     "AWS::Diagram::Resource",
+					Width:    1200,
+					MinWidth: 400,
+					Margin:   &types.Spacing{Left: &left},
+					Padding:  &types.Spacing{Left: &left},
+				},
+			},
+		},
+	}
+
+	actualResources := make(map[string]*types.Resource)
+	if err := loadResources(template, definition.DefinitionStructure{}, actualResources); err != nil {
+		t.Fatalf("loadResources failed: %v", err)
+	}
+
+	expected := new(types.Resource).Init()
+	expected.SetSize(1200, 0)
+	expected.SetMinWidth(400)
+	expected.SetMarginOverride(types.Spacing{Left: &left})
+	expected.SetPaddingOverride(types.Spacing{Left: &left})
+	if !reflect.DeepEqual(actualResources["VPC"], expected) {
+		t.Errorf("VPC deep comparison failed.\nExpected: %+v\nActual: %+v", expected, actualResources["VPC"])
+	}
+
+	template.Resources["VPC"] = Resource{Type: "AWS::Diagram::Resource", Height: -1}
+	if err := loadResources(template, definition.DefinitionStructure{}, make(map[string]*types.Resource)); err == nil {
+		t.Error("expected error for negative Height")
+	}
+}
+
+func TestLoadResourcesWithOffsetAndPin(t *testing.T) {
+	template := &TemplateStruct{
+		Diagram: Diagram{
+			Resources: map[string]Resource{
+				"Instance": {
+					Type:   "AWS::Diagram::Resource",
+					Offset: &Point{X: -10, Y: 20},
+					Pin:    &Point{X: 100, Y: 50},
+				},
+			},
+		},
+	}
+
+	actualResources := make(map[string]*types.Resource)
+	if err := loadResources(template, definition.DefinitionStructure{}, actualResources); err != nil {
+		t.Fatalf("loadResources failed: %v", err)
+	}
+
+	expected := new(types.Resource).Init()
+	expected.SetOffset(image.Point{X: -10, Y: 20})
+	expected.SetPin(image.Point{X: 100, Y: 50})
+	if !reflect.DeepEqual(actualResources["Instance"], expected) {
+		t.Errorf("Instance deep comparison failed.\nExpected: %+v\nActual: %+v", expected, actualResources["Instance"])
+	}
+
+	template.Resources["Instance"] = Resource{Type: "AWS::Diagram::Resource", Pin: &Point{X: -1}}
+	if err := loadResources(template, definition.DefinitionStructure{}, make(map[string]*types.Resource)); err == nil {
+		t.Error("expected error for negative Pin")
+	}
+}
+
+func TestConvertWaypoints(t *testing.T) {
+	nat := new(types.Resource).Init()
+	resources := map[string]*types.Resource{"NATGW": nat}
+
+	waypoints, err := convertWaypoints(Link{Via: []LinkVia{{Resource: "NATGW", Position: "S", Offset: 20}}}, resources)
+	if err != nil {
+		t.Fatalf("convertWaypoints failed: %v", err)
+	}
+	expected := []types.Waypoint{{Resource: nat, Position: types.WINDROSE_S, Offset: 20}}
+	if !reflect.DeepEqual(waypoints, expected) {
+		t.Errorf("expected %+v, got %+v", expected, waypoints)
+	}
+
+	waypoints, err = convertWaypoints(Link{Waypoints: []Point{{X: 10, Y: 20}}}, resources)
+	if err != nil {
+		t.Fatalf("convertWaypoints failed: %v", err)
+	}
+	expected = []types.Waypoint{{Point: image.Point{X: 10, Y: 20}}}
+	if !reflect.DeepEqual(waypoints, expected) {
+		t.Errorf("expected %+v, got %+v", expected, waypoints)
+	}
+
+	if _, err := convertWaypoints(Link{Via: []LinkVia{{Resource: "Unknown"}}}, resources); err == nil {
+		t.Error("expected error for unknown Via resource")
+	}
+	if _, err := convertWaypoints(Link{Waypoints: []Point{{}}, Via: []LinkVia{{Resource: "NATGW"}}}, resources); err == nil {
+		t.Error("expected error for both Waypoints and Via")
+	}
+}
+
+func TestConvertLabelAlongPath(t *testing.T) {
+	labelType := "along-path"
+	label, err := convertLabel(&LinkLabel{Type: &labelType, Title: "HTTPS:443"})
+	if err != nil {
+		t.Fatalf("convertLabel failed: %v", err)
+	}
+	if label.Type != types.LINK_LABEL_TYPE_ALONG_PATH || label.Title != "HTTPS:443" {
+		t.Errorf("expected an along-path label HTTPS:443, got %+v", label)
+	}
+}
+
+func TestLoadLinksWithLineJump(t *testing.T) {
+	newTemplate := func(diagramJump, linkJump string) *TemplateStruct {
+		return &TemplateStruct{
+			Diagram: Diagram{
+				LineJump: diagramJump,
+				Links:    []Link{{Source: "A", Target: "B", LineJump: linkJump}},
+			},
+		}
+	}
+	for _, tt := range []struct {
+		diagramJump, linkJump string
+		wantErr               bool
+	}{
+		{"arc", "", false},
+		{"arc", "none", false},
+		{"", "gap", false},
+		{"", "zigzag", true},
+	} {
+		resources := map[string]*types.Resource{"A": new(types.Resource).Init(), "B": new(types.Resource).Init()}
+		err := loadLinks(newTemplate(tt.diagramJump, tt.linkJump), resources)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("LineJump %q/%q: expected error %v, got %v", tt.diagramJump, tt.linkJump, tt.wantErr, err)
+		}
+	}
+}
+
+func TestLoadLinksWithArrowHeads(t *testing.T) {
+	for _, tt := range []struct {
+		source, target string
+		wantErr        bool
+	}{
+		{"", "Open", false},
+		{"Diamond", "ZeroOrMany", false},
+		{"Diamnod", "Open", true},
+		{"", "open", true},
+	} {
+		template := &TemplateStruct{
+			Diagram: Diagram{
+				Links: []Link{{
+					Source:          "A",
+					Target:          "B",
+					SourceArrowHead: types.ArrowHead{Type: tt.source},
+					TargetArrowHead: types.ArrowHead{Type: tt.target},
+				}},
+			},
+		}
+		resources := map[string]*types.Resource{"A": new(types.Resource).Init(), "B": new(types.Resource).Init()}
+		err := loadLinks(template, resources)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("arrow heads %q/%q: expected error %v, got %v", tt.source, tt.target, tt.wantErr, err)
+		}
+		if err != nil && !strings.Contains(err.Error(), "supported arrow head types are") {
+			t.Errorf("expected the supported arrow head types in the error, got %v", err)
+		}
+	}
+}
+
+func TestAssociateChildrenWithMembers(t *testing.T) {
+	template := &TemplateStruct{
+		Diagram: Diagram{
+			Resources: map[string]Resource{
+				"Canvas":    {Type: "AWS::Diagram::Canvas", Children: []string{"Instance1", "Instance2"}},
+				"Instance1": {Type: "AWS::Diagram::Resource"},
+				"Instance2": {Type: "AWS::Diagram::Resource"},
+				"SG":        {Type: "AWS::Diagram::Overlay", Members: []string{"Instance1", "Instance2", "Unknown"}},
+			},
+		},
+	}
+
+	resources := make(map[string]*types.Resource)
+	if err := loadResources(template, definition.DefinitionStructure{}, resources); err != nil {
+		t.Fatalf("loadResources failed: %v", err)
+	}
+	if err := associateChildren(template, resources); err != nil {
+		t.Fatalf("associateChildren failed: %v", err)
+	}
+	overlays := resources["Canvas"].GetOverlays()
+	if len(overlays) != 1 || overlays[0] != resources["SG"] {
+		t.Errorf("expected SG to be the only overlay, got %v", overlays)
+	}
+
+	template.Resources["SG"] = Resource{Type: "AWS::Diagram::Overlay", Children: []string{"Instance1"}, Members: []string{"Instance2"}}
+	resources = make(map[string]*types.Resource)
+	if err := loadResources(template, definition.DefinitionStructure{}, resources); err != nil {
+		t.Fatalf("loadResources failed: %v", err)
+	}
+	if err := associateChildren(template, resources); err == nil {
+		t.Error("expected error for a resource with both Children and Members")
+	}
+}
+
 func TestIsAllowedDefinitionURL(t *testing.T) {
 	tests := []struct {
 		name    string
@@ -330,3 +572,82 @@ func TestIsAllowedDefinitionURL(t *testing.T) {
 		})
 	}
 }
+
+func TestConvertPosition(t *testing.T) {
+	vpc := new(types.Resource).Init()
+	if err := setPort(vpc, "ingress", "W", nil); err != nil {
+		t.Fatalf("setPort failed: %v", err)
+	}
+	for _, tt := range []struct {
+		position string
+		windrose types.Windrose
+		anchor   *types.Anchor
+	}{
+		{"NNE", types.WINDROSE_NNE, nil},
+		{"E@0.3", types.WINDROSE_E, &types.Anchor{Side: types.WINDROSE_E, At: 0.3}},
+		{"ingress", types.WINDROSE_W, &types.Anchor{Side: types.WINDROSE_W, At: 0.5}},
+	} {
+		windrose, anchor, err := convertPosition(vpc, tt.position)
+		if err != nil {
+			t.Fatalf("convertPosition(%s) failed: %v", tt.position, err)
+		}
+		if windrose != tt.windrose || !reflect.DeepEqual(anchor, tt.anchor) {
+			t.Errorf("%s: expected %v %v, got %v %v", tt.position, tt.windrose, tt.anchor, windrose, anchor)
+		}
+	}
+	if _, _, err := convertPosition(vpc, "egress"); err == nil {
+		t.Error("expected error for unknown port")
+	}
+	if err := setPort(vpc, "corner", "NE", nil); err == nil {
+		t.Error("expected error for a port on a corner")
+	}
+}
+
+func TestSetBorderStyle(t *testing.T) {
+	width := 3
+	for _, tt := range []struct {
+		borderType  string
+		width       *int
+		dashPattern []float64
+		wantErr     bool
+	}{
+		{"dotted", nil, nil, false},
+		{"dash-dot", &width, nil, false},
+		{"", nil, []float64{8, 4}, false},
+		{"wavy", nil, nil, true},
+		{"", nil, []float64{8, 4, 2}, true},
+		{"", nil, []float64{8, 0}, true},
+	} {
+		err := setBorderStyle(new(types.Resource).Init(), tt.borderType, tt.width, tt.dashPattern)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%q %v: expected error %v, got %v", tt.borderType, tt.dashPattern, tt.wantErr, err)
+		}
+	}
+}
+
+func TestSetFillAndShadow(t *testing.T) {
+	for _, tt := range []struct {
+		fill    definition.DefinitionFill
+		wantErr bool
+	}{
+		{definition.DefinitionFill{Color: "rgba(255,255,255,255)"}, false},
+		{definition.DefinitionFill{Type: "linear-gradient", From: "rgba(255,255,255,255)", To: "rgba(0,0,0,255)", Angle: 90}, false},
+		{definition.DefinitionFill{Type: "linear-gradient", From: "rgba(255,255,255,255)"}, true},
+		{definition.DefinitionFill{Type: "radial-gradient", Color: "rgba(255,255,255,255)"}, true},
+	} {
+		err := setFill(new(types.Resource).Init(), &tt.fill)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%+v: expected error %v, got %v", tt.fill, tt.wantErr, err)
+		}
+	}
+
+	if err := setShadow(new(types.Resource).Init(), &definition.DefinitionShadow{Blur: 8}); err != nil {
+		t.Errorf("expected a default shadow color, got %v", err)
+	}
+	if err := setShadow(new(types.Resource).Init(), &definition.DefinitionShadow{Blur: -1}); err == nil {
+		t.Error("expected an error for a negative blur")
+	}
+	if err := setBorder(new(types.Resource).Init(), &definition.DefinitionBorder{Color: "rgba(0,0,0,255)", CornerRadius: -4}); err == nil {
+		t.Error("expected an error for a negative corner radius")
+	}
+}
@@ -72,12 +72,27 @@ func CreateDiagramFromDacFile(inputfile string, outputfile *string, opts *Create
 
 	log.Infof("input file path: %s\n", inputfile)
 
+	_, _, resources, err := loadDacFile(inputfile, opts)
+	if err != nil {
+		return err
+	}
+
+	if err := createDiagram(resources, outputfile, opts); err != nil {
+		return fmt.Errorf("failed to create diagram: %w", err)
+	}
+	return nil
+}
+
+// loadDacFile decodes a DAC file and loads its resources and links. It also returns the YAML
+// after the template processing.
+func loadDacFile(inputfile string, opts *CreateOptions) (*TemplateStruct, []byte, map[string]*types.Resource, error) {
+
 	var template TemplateStruct
 
 	// Get the template content
 	data, err := getTemplate(inputfile)
 	if err != nil {
-		return fmt.Errorf("failed to get template: %w", err)
+		return nil, nil, nil, fmt.Errorf("failed to get template: %w", err)
 	}
 
 	// Process the template with variables
@@ -88,7 +103,7 @@ func CreateDiagramFromDacFile(inputfile string, outputfile *string, opts *Create
 			log.Infof("processed template: \n%s", string(processedData))
 		}
 		if err != nil {
-			return fmt.Errorf("failed to process template: %w", err)
+			return nil, nil, nil, fmt.Errorf("failed to process template: %w", err)
 		}
 	} else {
 		processedData = data
@@ -102,7 +117,15 @@ func CreateDiagramFromDacFile(inputfile string, outputfile *string, opts *Create
 		if !opts.IsGoTemplate && slices.Contains(processedData, '{') {
 			log.Warn("Is this file a template, containing template control syntax such as {{ that according to text/template package? If so, add the -t (--tempate) option.")
 		}
-		return fmt.Errorf("failed to decode YAML: %w", err)
+		return nil, nil, nil, fmt.Errorf("failed to decode YAML: %w", err)
+	}
+
+	if opts.Theme != "" {
+		template.Theme = opts.Theme
+	}
+	theme, err := template.theme()
+	if err != nil {
+		return nil, nil, nil, err
 	}
 
 	var ds definition.DefinitionStructure
@@ -128,32 +151,42 @@ func CreateDiagramFromDacFile(inputfile string, outputfile *string, opts *Create
 		}
 		// OverrideDefFile is for testing, so allow untrusted URLs
 		if err := loadDefinitionFiles(&overrideDefTemplate, &ds, true); err != nil {
-			return fmt.Errorf("failed to load override definition files: %w", err)
+			return nil, nil, nil, fmt.Errorf("failed to load override definition files: %w", err)
 		}
 		log.Infof("overrideDefTemplate: %+v", overrideDefTemplate)
 	} else {
 		if err := loadDefinitionFiles(&template, &ds, opts.AllowUntrustedDefinitions); err != nil {
-			return fmt.Errorf("failed to load definition files: %w", err)
+			return nil, nil, nil, fmt.Errorf("failed to load definition files: %w", err)
 		}
 	}
 
+	log.Info("Apply styles")
+	if err := applyStyles(&template); err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to apply styles: %w", err)
+	}
+
+	log.Info("Resolve colors")
+	if err := resolveColors(&template, &ds); err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to resolve colors: %w", err)
+	}
+
+	log.Infof("Apply %s theme", template.Theme)
+	applyTheme(theme, &ds)
+
 	log.Info("Load Resources section")
 	if err := loadResources(&template, ds, resources); err != nil {
-		return fmt.Errorf("failed to load resources: %w", err)
+		return nil, nil, nil, fmt.Errorf("failed to load resources: %w", err)
 	}
 
 	log.Info("Associate children with parent resources")
 	if err := associateChildren(&template, resources); err != nil {
-		return fmt.Errorf("failed to associate children: %w", err)
+		return nil, nil, nil, fmt.Errorf("failed to associate children: %w", err)
 	}
 
 	log.Info("Add Links section")
 	if err := loadLinks(&template, resources); err != nil {
-		return fmt.Errorf("failed to load links: %w", err)
+		return nil, nil, nil, fmt.Errorf("failed to load links: %w", err)
 	}
 
-	if err := createDiagram(resources, outputfile, opts); err != nil {
-		return fmt.Errorf("failed to create diagram: %w", err)
-	}
-	return nil
+	return &template, processedData, resources, nil
 }
@@ -0,0 +1,209 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package ctl
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/awslabs/diagram-as-code/internal/types"
+	log "github.com/sirupsen/logrus"
+	"gopkg.in/yaml.v3"
+)
+
+// FreezeLayoutFromDacFile lays out a DAC file and writes a copy of it where the computed layout is
+// explicit: the size of every group, the position of every child with Pin (and Offset when it is
+// outside of its parent) and the positions of every link. The frozen diagram doesn't depend on
+// the layout algorithm anymore.
+func FreezeLayoutFromDacFile(inputfile string, outputfile string, opts *CreateOptions) error {
+
+	log.Infof("input file path: %s\n", inputfile)
+
+	if err := CheckOutputFileOverwrite(outputfile, opts.OverwriteMode); err != nil {
+		return err
+	}
+
+	template, data, resources, err := loadDacFile(inputfile, opts)
+	if err != nil {
+		return err
+	}
+	canvas, exists := resources["Canvas"]
+	if !exists {
+		return fmt.Errorf("Canvas resource not found")
+	}
+	if err := layoutDiagram(canvas, resources); err != nil {
+		return err
+	}
+
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return fmt.Errorf("failed to decode YAML: %w", err)
+	}
+	if err := freezeDocument(&doc, template, resources); err != nil {
+		return fmt.Errorf("failed to freeze layout: %w", err)
+	}
+
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(&doc); err != nil {
+		return fmt.Errorf("failed to encode YAML: %w", err)
+	}
+	if err := enc.Close(); err != nil {
+		return fmt.Errorf("failed to encode YAML: %w", err)
+	}
+
+	log.Infof("Save %s\n", outputfile)
+	if err := os.WriteFile(outputfile, buf.Bytes(), 0600); err != nil {
+		return fmt.Errorf("error writing output file: %w", err)
+	}
+	return nil
+}
+
+// freezeDocument writes the layout of the resources into the YAML document of the DAC file,
+// keeping everything else including the comments.
+func freezeDocument(doc *yaml.Node, template *TemplateStruct, resources map[string]*types.Resource) error {
+	if len(doc.Content) == 0 {
+		return fmt.Errorf("empty document")
+	}
+	diagram := mappingValue(doc.Content[0], "Diagram")
+	if diagram == nil {
+		return fmt.Errorf("Diagram section not found")
+	}
+	resourcesNode := mappingValue(diagram, "Resources")
+	if resourcesNode == nil {
+		return fmt.Errorf("Resources section not found")
+	}
+
+	names := map[*types.Resource]string{}
+	for name, resource := range resources {
+		names[resource] = name
+	}
+	var freeze func(parent *types.Resource) error
+	freeze = func(parent *types.Resource) error {
+		node := mappingValue(resourcesNode, names[parent])
+		if node == nil {
+			return fmt.Errorf("resource %s not found in the Resources section", names[parent])
+		}
+		b := parent.GetBindings()
+		setMappingValue(node, "Width", intNode(b.Dx()))
+		setMappingValue(node, "Height", intNode(b.Dy()))
+		for _, child := range parent.GetChildren() {
+			childNode := mappingValue(resourcesNode, names[child])
+			if childNode == nil {
+				return fmt.Errorf("resource %s not found in the Resources section", names[child])
+			}
+			position := child.GetBindings().Min.Sub(parent.GetPinOrigin())
+			pin := image.Point{max(position.X, 0), max(position.Y, 0)}
+			setMappingValue(childNode, "Pin", pointNode(pin))
+			if offset := position.Sub(pin); offset != (image.Point{}) {
+				setMappingValue(childNode, "Offset", pointNode(offset))
+			} else {
+				deleteMappingKey(childNode, "Offset")
+			}
+			if len(child.GetChildren()) != 0 {
+				if err := freeze(child); err != nil {
+					return err
+				}
+			} else if size := child.GetBindings().Size(); size != child.GetIconBounds().Size() {
+				// Resources without icon like the columns of an az-matrix
+				setMappingValue(childNode, "Width", intNode(size.X))
+				setMappingValue(childNode, "Height", intNode(size.Y))
+			}
+		}
+		return nil
+	}
+	if err := freeze(resources["Canvas"]); err != nil {
+		return err
+	}
+
+	linksNode := mappingValue(diagram, "Links")
+	if linksNode == nil {
+		return nil
+	}
+	used := map[*types.Link]bool{}
+	for i, v := range template.Links {
+		source, ok := resources[v.Source]
+		if !ok || i >= len(linksNode.Content) {
+			continue
+		}
+		for _, link := range source.GetLinks() {
+			if used[link] || link.Source != source || link.Target != resources[v.Target] {
+				continue
+			}
+			used[link] = true
+			setMappingValue(linksNode.Content[i], "SourcePosition", stringNode(frozenPosition(v.SourcePosition, link.SourcePosition, link.GetSourceAnchor())))
+			setMappingValue(linksNode.Content[i], "TargetPosition", stringNode(frozenPosition(v.TargetPosition, link.TargetPosition, link.GetTargetAnchor())))
+			break
+		}
+	}
+	return nil
+}
+
+// frozenPosition returns the position of an end of a link to write: the side chosen by the layout,
+// or the fractional anchor or the port the end is attached to
+func frozenPosition(position string, side types.Windrose, anchor *types.Anchor) string {
+	if anchor == nil {
+		return side.String()
+	}
+	if !strings.Contains(position, "@") {
+		// Ports are the only other positions with an anchor
+		return position
+	}
+	return anchor.String()
+}
+
+func mappingValue(node *yaml.Node, key string) *yaml.Node {
+	if node == nil || node.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1]
+		}
+	}
+	return nil
+}
+
+func setMappingValue(node *yaml.Node, key string, value *yaml.Node) {
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			node.Content[i+1] = value
+			return
+		}
+	}
+	node.Content = append(node.Content, stringNode(key), value)
+}
+
+func deleteMappingKey(node *yaml.Node, key string) {
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			node.Content = append(node.Content[:i], node.Content[i+2:]...)
+			return
+		}
+	}
+}
+
+func stringNode(value string) *yaml.Node {
+	return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: value}
+}
+
+func intNode(value int) *yaml.Node {
+	return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!int", Value: strconv.Itoa(value)}
+}
+
+func pointNode(p image.Point) *yaml.Node {
+	return &yaml.Node{
+		Kind:  yaml.MappingNode,
+		Style: yaml.FlowStyle,
+		Content: []*yaml.Node{
+			stringNode("X"), intNode(p.X),
+			stringNode("Y"), intNode(p.Y),
+		},
+	}
+}
@@ -0,0 +1,141 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package ctl
+
+import (
+	"bytes"
+	"image"
+	"strings"
+	"testing"
+
+	"github.com/awslabs/diagram-as-code/internal/definition"
+	"github.com/awslabs/diagram-as-code/internal/types"
+	"gopkg.in/yaml.v3"
+)
+
+const freezeTestDac = `Diagram:
+  Resources:
+    Canvas:
+      Type: AWS::Diagram::Canvas
+      Direction: horizontal
+      Children:
+        - Group
+        - Instance3
+    # The group is laid out on a grid
+    Group:
+      Type: AWS::Diagram::Resource
+      Title: Group
+      Direction: grid
+      Columns: 2
+      Children:
+        - Instance1
+        - Instance2
+    Instance1:
+      Type: AWS::Diagram::Resource
+    Instance2:
+      Type: AWS::Diagram::Resource
+      Offset: {X: 10, Y: -200}
+      Ports:
+        in: {Side: E, At: 0.25}
+    Instance3:
+      Type: AWS::Diagram::Resource
+  Links:
+    - Source: Instance1
+      Target: Instance3
+    - Source: Instance3
+      SourcePosition: W@0.8
+      Target: Instance2
+      TargetPosition: in
+`
+
+func layoutTestDac(t *testing.T, data []byte) (*TemplateStruct, map[string]*types.Resource) {
+	var template TemplateStruct
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(&template); err != nil {
+		t.Fatalf("failed to decode YAML: %v\n%s", err, data)
+	}
+	resources := make(map[string]*types.Resource)
+	if err := loadResources(&template, definition.DefinitionStructure{}, resources); err != nil {
+		t.Fatalf("loadResources failed: %v", err)
+	}
+	if err := associateChildren(&template, resources); err != nil {
+		t.Fatalf("associateChildren failed: %v", err)
+	}
+	if err := loadLinks(&template, resources); err != nil {
+		t.Fatalf("loadLinks failed: %v", err)
+	}
+	if err := layoutDiagram(resources["Canvas"], resources); err != nil {
+		t.Fatalf("layoutDiagram failed: %v", err)
+	}
+	return &template, resources
+}
+
+func TestFreezeDocument(t *testing.T) {
+	template, resources := layoutTestDac(t, []byte(freezeTestDac))
+
+	var doc yaml.Node
+	if err := yaml.Unmarshal([]byte(freezeTestDac), &doc); err != nil {
+		t.Fatalf("failed to decode YAML: %v", err)
+	}
+	if err := freezeDocument(&doc, template, resources); err != nil {
+		t.Fatalf("freezeDocument failed: %v", err)
+	}
+	frozen, err := yaml.Marshal(&doc)
+	if err != nil {
+		t.Fatalf("failed to encode YAML: %v", err)
+	}
+	if !strings.Contains(string(frozen), "# The group is laid out on a grid") {
+		t.Errorf("expected comments to be kept, got:\n%s", frozen)
+	}
+
+	frozenTemplate, frozenResources := layoutTestDac(t, frozen)
+	for name, resource := range resources {
+		if resource.GetBindings() != frozenResources[name].GetBindings() {
+			t.Errorf("%s: expected bindings %v, got %v", name, resource.GetBindings(), frozenResources[name].GetBindings())
+		}
+	}
+
+	// The group grows to fit Instance2 moved by its offset, which is replaced with a pin
+	expected := resources["Instance2"].GetBindings().Min.Sub(resources["Group"].GetPinOrigin())
+	if pin := frozenTemplate.Resources["Instance2"].Pin; pin == nil || image.Pt(pin.X, pin.Y) != expected {
+		t.Errorf("expected Instance2 to be pinned at %v, got %+v", expected, pin)
+	}
+	if offset := frozenTemplate.Resources["Instance2"].Offset; offset != nil {
+		t.Errorf("expected the offset of Instance2 to be removed, got %+v", offset)
+	}
+	link := resources["Instance1"].GetLinks()[0]
+	frozenLink := frozenTemplate.Links[0]
+	if frozenLink.SourcePosition != link.SourcePosition.String() || frozenLink.TargetPosition != link.TargetPosition.String() {
+		t.Errorf("expected link positions %v and %v, got %s and %s", link.SourcePosition, link.TargetPosition, frozenLink.SourcePosition, frozenLink.TargetPosition)
+	}
+
+	// Fractional anchors and ports are kept
+	anchored := frozenTemplate.Links[1]
+	if anchored.SourcePosition != "W@0.8" || anchored.TargetPosition != "in" {
+		t.Errorf("expected link positions W@0.8 and in, got %s and %s", anchored.SourcePosition, anchored.TargetPosition)
+	}
+	linkFrom := func(resources map[string]*types.Resource, name string) *types.Link {
+		for _, link := range resources[name].GetLinks() {
+			if link.Source == resources[name] {
+				return link
+			}
+		}
+		t.Fatalf("no link from %s", name)
+		return nil
+	}
+	link = linkFrom(resources, "Instance3")
+	frozenLinkAnchors := linkFrom(frozenResources, "Instance3")
+	for _, tc := range []struct {
+		name             string
+		expected, frozen *types.Anchor
+	}{
+		{"source", link.GetSourceAnchor(), frozenLinkAnchors.GetSourceAnchor()},
+		{"target", link.GetTargetAnchor(), frozenLinkAnchors.GetTargetAnchor()},
+	} {
+		if tc.expected == nil || tc.frozen == nil || *tc.expected != *tc.frozen {
+			t.Errorf("%s: expected anchor %v, got %v", tc.name, tc.expected, tc.frozen)
+		}
+	}
+}
@@ -0,0 +1,140 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package ctl
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/awslabs/diagram-as-code/internal/types"
+)
+
+// Style is a class declared in the Styles section. Resources and links using the class with their
+// Class field take the fields they don't set themselves from it; the fields of the other kind are
+// ignored, so that one class can mark both the resources and the links of a path.
+type Style struct {
+	// Resources
+	FillColor         string            `yaml:"FillColor"`
+	Fill              *Fill             `yaml:"Fill"`
+	TitleColor        string            `yaml:"TitleColor"`
+	Font              string            `yaml:"Font"`
+	HeaderAlign       string            `yaml:"HeaderAlign"`
+	IconFill          *ResourceIconFill `yaml:"IconFill"`
+	BorderColor       string            `yaml:"BorderColor"`
+	BorderType        string            `yaml:"BorderType"`
+	BorderWidth       *int              `yaml:"BorderWidth"`
+	BorderDashPattern []float64         `yaml:"BorderDashPattern"`
+	CornerRadius      *int              `yaml:"CornerRadius"`
+	Shadow            *Shadow           `yaml:"Shadow"`
+	// Links
+	LineWidth       int              `yaml:"LineWidth"`
+	LineColor       string           `yaml:"LineColor"`
+	LineStyle       string           `yaml:"LineStyle"`
+	DashPattern     []float64        `yaml:"DashPattern"`
+	LineJump        string           `yaml:"LineJump"`
+	SourceArrowHead *types.ArrowHead `yaml:"SourceArrowHead"`
+	TargetArrowHead *types.ArrowHead `yaml:"TargetArrowHead"`
+}
+
+// applyStyles fills the fields of the resources and the links left unset with the classes named in
+// their Class field. Class may name several classes separated by spaces, the later ones overriding
+// the earlier ones. As the fields of the template are applied after the definition and the
+// preset, the classes come between the preset and the template.
+func applyStyles(template *TemplateStruct) error {
+	for _, k := range sortedKeys(template.Resources) {
+		v := template.Resources[k]
+		styles, err := classStyles(template.Styles, v.Class)
+		if err != nil {
+			return fmt.Errorf("Resources.%s.Class: %w", k, err)
+		}
+		for _, style := range styles {
+			style.applyToResource(&v)
+		}
+		template.Resources[k] = v
+	}
+	for i := range template.Links {
+		v := &template.Links[i]
+		styles, err := classStyles(template.Styles, v.Class)
+		if err != nil {
+			return fmt.Errorf("Links[%d] (%s -> %s).Class: %w", i, v.Source, v.Target, err)
+		}
+		for _, style := range styles {
+			style.applyToLink(v)
+		}
+	}
+	return nil
+}
+
+// classStyles returns the styles of the classes, the last one first
+func classStyles(styles map[string]Style, class string) ([]Style, error) {
+	names := strings.Fields(class)
+	r := []Style{}
+	for i := len(names) - 1; i >= 0; i-- {
+		style, ok := styles[names[i]]
+		if !ok {
+			return nil, fmt.Errorf("unknown style class %s, declare it in the Styles section", names[i])
+		}
+		r = append(r, style)
+	}
+	return r, nil
+}
+
+func (s Style) applyToResource(v *Resource) {
+	setString(&v.FillColor, s.FillColor)
+	setString(&v.TitleColor, s.TitleColor)
+	setString(&v.Font, s.Font)
+	setString(&v.HeaderAlign, s.HeaderAlign)
+	setString(&v.BorderColor, s.BorderColor)
+	setString(&v.BorderType, s.BorderType)
+	// The pointed values are copied, as the colors are resolved in place for every resource
+	if v.Fill == nil && s.Fill != nil {
+		fill := *s.Fill
+		v.Fill = &fill
+	}
+	if v.IconFill == nil && s.IconFill != nil {
+		iconFill := *s.IconFill
+		if iconFill.Color != nil {
+			c := *iconFill.Color
+			iconFill.Color = &c
+		}
+		v.IconFill = &iconFill
+	}
+	if v.BorderWidth == nil {
+		v.BorderWidth = s.BorderWidth
+	}
+	if v.BorderDashPattern == nil {
+		v.BorderDashPattern = s.BorderDashPattern
+	}
+	if v.CornerRadius == nil {
+		v.CornerRadius = s.CornerRadius
+	}
+	if v.Shadow == nil && s.Shadow != nil {
+		shadow := *s.Shadow
+		v.Shadow = &shadow
+	}
+}
+
+func (s Style) applyToLink(v *Link) {
+	if v.LineWidth == 0 {
+		v.LineWidth = s.LineWidth
+	}
+	setString(&v.LineColor, s.LineColor)
+	setString(&v.LineStyle, s.LineStyle)
+	setString(&v.LineJump, s.LineJump)
+	if v.DashPattern == nil {
+		v.DashPattern = s.DashPattern
+	}
+	if v.SourceArrowHead == (types.ArrowHead{}) && s.SourceArrowHead != nil {
+		v.SourceArrowHead = *s.SourceArrowHead
+	}
+	if v.TargetArrowHead == (types.ArrowHead{}) && s.TargetArrowHead != nil {
+		v.TargetArrowHead = *s.TargetArrowHead
+	}
+}
+
+func setString(field *string, value string) {
+	if *field == "" {
+		*field = value
+	}
+}
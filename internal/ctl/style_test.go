@@ -0,0 +1,65 @@
+package ctl
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/awslabs/diagram-as-code/internal/types"
+)
+
+func TestApplyStyles(t *testing.T) {
+	width, radius := 4, 8
+	template := TemplateStruct{Diagram: Diagram{
+		Styles: map[string]Style{
+			"critical": {BorderColor: "red", TitleColor: "red", BorderWidth: &width, LineColor: "red", LineWidth: 4},
+			"muted":    {BorderColor: "gray", CornerRadius: &radius, LineStyle: "dashed", TargetArrowHead: &types.ArrowHead{Type: "Open"}},
+		},
+		Resources: map[string]Resource{
+			"VPC":    {Type: "AWS::EC2::VPC", Class: "critical muted", TitleColor: "black"},
+			"Subnet": {Type: "AWS::EC2::Subnet"},
+		},
+		Links: []Link{
+			{Source: "VPC", Target: "Subnet", Class: "muted critical"},
+			{Source: "Subnet", Target: "VPC", Class: "muted", TargetArrowHead: types.ArrowHead{Type: "Default"}},
+		},
+	}}
+	if err := applyStyles(&template); err != nil {
+		t.Fatalf("applyStyles failed: %v", err)
+	}
+
+	vpc := template.Resources["VPC"]
+	// The later class overrides the earlier one, and the resource overrides both
+	if vpc.BorderColor != "gray" {
+		t.Errorf("expected the border color of the later class, got %s", vpc.BorderColor)
+	}
+	if vpc.TitleColor != "black" {
+		t.Errorf("expected the title color of the resource, got %s", vpc.TitleColor)
+	}
+	if vpc.BorderWidth == nil || *vpc.BorderWidth != 4 || vpc.CornerRadius == nil || *vpc.CornerRadius != 8 {
+		t.Errorf("expected the border width and the corner radius of the classes, got %v and %v", vpc.BorderWidth, vpc.CornerRadius)
+	}
+	if c := template.Resources["Subnet"].BorderColor; c != "" {
+		t.Errorf("expected no style without a class, got %s", c)
+	}
+
+	link := template.Links[0]
+	if link.LineColor != "red" || link.LineWidth != 4 || link.LineStyle != "dashed" || link.TargetArrowHead.Type != "Open" {
+		t.Errorf("expected the link fields of both classes, got %+v", link)
+	}
+	if head := template.Links[1].TargetArrowHead.Type; head != "Default" {
+		t.Errorf("expected the arrow head of the link, got %s", head)
+	}
+
+	for _, tt := range []struct {
+		template TemplateStruct
+		field    string
+	}{
+		{TemplateStruct{Diagram: Diagram{Resources: map[string]Resource{"VPC": {Class: "critical"}}}}, "Resources.VPC.Class"},
+		{TemplateStruct{Diagram: Diagram{Links: []Link{{Source: "A", Target: "B", Class: "muted"}}}}, "Links[0] (A -> B).Class"},
+	} {
+		err := applyStyles(&tt.template)
+		if err == nil || !strings.HasPrefix(err.Error(), tt.field+":") {
+			t.Errorf("expected an error on %s, got %v", tt.field, err)
+		}
+	}
+}
@@ -0,0 +1,99 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package ctl
+
+import (
+	"fmt"
+	"image/color"
+
+	"github.com/awslabs/diagram-as-code/internal/definition"
+	"github.com/awslabs/diagram-as-code/internal/types"
+)
+
+// Theme is a set of default colors of a diagram, chosen with the Theme field of a DAC file or with
+// the --theme option
+type Theme struct {
+	Background color.RGBA // fill of the canvas
+	Text       color.RGBA // titles without a color of their own
+	Line       color.RGBA // links without a line color
+	Border     color.RGBA // groups without a border color
+	// Swaps replaces the colors of the definitions, such as the border and fill palettes of groups
+	Swaps map[color.RGBA]color.RGBA
+}
+
+const DEFAULT_THEME = "light"
+
+var themes = map[string]*Theme{
+	"light": {
+		Background: color.RGBA{255, 255, 255, 255},
+		Text:       color.RGBA{0, 0, 0, 255},
+		Line:       color.RGBA{0, 0, 0, 255},
+		Border:     color.RGBA{0, 0, 0, 255},
+	},
+	"dark": {
+		Background: color.RGBA{22, 30, 45, 255},
+		Text:       color.RGBA{242, 243, 243, 255},
+		Line:       color.RGBA{213, 219, 219, 255},
+		Border:     color.RGBA{213, 219, 219, 255},
+		Swaps: map[color.RGBA]color.RGBA{
+			{0, 0, 0, 255}:       {242, 243, 243, 255},
+			{255, 255, 255, 255}: {22, 30, 45, 255},
+			{0, 164, 166, 255}:   {0, 194, 196, 255},
+			{122, 161, 22, 255}:  {150, 196, 40, 255},
+			{29, 137, 0, 255}:    {61, 186, 30, 255},
+			{237, 113, 0, 255}:   {255, 153, 0, 255},
+			{125, 137, 152, 255}: {160, 172, 186, 255},
+			{231, 21, 123, 255}:  {255, 94, 170, 255},
+			{105, 59, 197, 255}:  {160, 120, 245, 255},
+		},
+	},
+}
+
+// theme returns the theme of the template, the light theme by default
+func (t *TemplateStruct) theme() (*Theme, error) {
+	name := t.Theme
+	if name == "" {
+		name = DEFAULT_THEME
+	}
+	theme, ok := themes[name]
+	if !ok {
+		return nil, fmt.Errorf("Theme: unknown theme %s, expected one of %v", name, sortedKeys(themes))
+	}
+	return theme, nil
+}
+
+// applyTheme swaps the colors of the definitions for those of the theme. It runs after
+// resolveColors, so that the colors are no longer palette references.
+func applyTheme(theme *Theme, ds *definition.DefinitionStructure) {
+	for _, k := range sortedKeys(ds.Definitions) {
+		def := ds.Definitions[k]
+		if def == nil {
+			continue
+		}
+		for _, field := range definitionColors(def) {
+			if *field == "" {
+				continue
+			}
+			// resolveColors has only warned about the invalid colors of unused definitions
+			c, err := stringToColor(*field)
+			if err != nil {
+				continue
+			}
+			if swap, ok := theme.Swaps[c]; ok {
+				*field = fmt.Sprintf("rgba(%d,%d,%d,%d)", swap.R, swap.G, swap.B, swap.A)
+			}
+		}
+	}
+}
+
+// setThemeDefaults gives a new resource the text color of the theme and, for a group, its border
+// color. The colors of the definition and of the template are set afterwards and override them.
+func setThemeDefaults(resource *types.Resource, theme *Theme, hasChildren bool) {
+	// Copy the text color, as the resource keeps a pointer to it and the theme is shared
+	text := theme.Text
+	resource.SetLabel(nil, &text, nil)
+	if hasChildren {
+		resource.SetBorderColor(theme.Border)
+	}
+}
@@ -0,0 +1,78 @@
+package ctl
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/awslabs/diagram-as-code/internal/definition"
+	"github.com/awslabs/diagram-as-code/internal/types"
+)
+
+func TestApplyTheme(t *testing.T) {
+	ds := definition.DefinitionStructure{Definitions: map[string]*definition.Definition{
+		"AWS::EC2::VPC": {
+			Label:  &definition.DefinitionLabel{Color: "rgba(0, 0, 0, 255)"},
+			Border: &definition.DefinitionBorder{Color: "rgba(105, 59, 197, 255)"},
+			Fill:   &definition.DefinitionFill{Color: "rgba(0, 0, 0, 0)"},
+		},
+	}}
+	template := TemplateStruct{Diagram: Diagram{Theme: "dark"}}
+	theme, err := template.theme()
+	if err != nil {
+		t.Fatalf("theme failed: %v", err)
+	}
+	applyTheme(theme, &ds)
+
+	vpc := ds.Definitions["AWS::EC2::VPC"]
+	if c := vpc.Label.Color; c != "rgba(242,243,243,255)" {
+		t.Errorf("expected the black label to be swapped for the text color, got %s", c)
+	}
+	if c := vpc.Border.Color; c != "rgba(160,120,245,255)" {
+		t.Errorf("expected the border to be swapped for its dark color, got %s", c)
+	}
+	if c := vpc.Fill.Color; c != "rgba(0, 0, 0, 0)" {
+		t.Errorf("expected the transparent fill to be kept, got %s", c)
+	}
+
+	if _, err := (&TemplateStruct{Diagram: Diagram{Theme: "sepia"}}).theme(); err == nil {
+		t.Error("expected an error on an unknown theme")
+	}
+	if theme, err := (&TemplateStruct{}).theme(); err != nil || theme != themes["light"] {
+		t.Errorf("expected the light theme by default, got %v (%v)", theme, err)
+	}
+}
+
+func TestLoadResourcesWithTheme(t *testing.T) {
+	template := &TemplateStruct{
+		Diagram: Diagram{
+			Theme: "dark",
+			Resources: map[string]Resource{
+				"Group":   {Type: "AWS::Diagram::Resource", Children: []string{"Box"}},
+				"Box":     {Type: "AWS::Diagram::Resource"},
+				"Overlay": {Type: "AWS::Diagram::Overlay"},
+				"Divider": {Type: "AWS::Diagram::Divider"},
+			},
+		},
+	}
+	resources := make(map[string]*types.Resource)
+	if err := loadResources(template, definition.DefinitionStructure{}, resources); err != nil {
+		t.Fatalf("loadResources failed: %v", err)
+	}
+
+	dark := themes["dark"]
+	for k, expected := range map[string]*types.Resource{
+		"Group":   new(types.Resource).Init(),
+		"Box":     new(types.Resource).Init(),
+		"Overlay": new(types.Overlay).Init(),
+		"Divider": new(types.Divider).Init(),
+	} {
+		text := dark.Text
+		expected.SetLabel(nil, &text, nil)
+		if k == "Group" {
+			expected.SetBorderColor(dark.Border)
+		}
+		if !reflect.DeepEqual(resources[k], expected) {
+			t.Errorf("%s: expected the colors of the dark theme.\nExpected: %+v\nActual: %+v", k, expected, resources[k])
+		}
+	}
+}
@@ -6,15 +6,16 @@ package definition
 import "fmt"
 
 type Definition struct {
-	Type          string              `yaml:"Type"`
-	Icon          *DefinitionIcon     `yaml:"Icon"`
-	Label         *DefinitionLabel    `yaml:"Label"`
-	Fill          *DefinitionFill     `yaml:"Fill"`
-	Border        *DefinitionBorder   `yaml:"Border"`
-	HeaderAlign   string              `yaml:"HeaderAlign"`
-	Directory     DefinitionDirectory `yaml:"Directory"`
-	ZipFile       DefinitionZipFile   `yaml:"ZipFile"`
-	CFn           DefinitionCFn       `yaml:"CFn"`
+	Type          string                    `yaml:"Type"`
+	Icon          *DefinitionIcon           `yaml:"Icon"`
+	Label         *DefinitionLabel          `yaml:"Label"`
+	Fill          *DefinitionFill           `yaml:"Fill"`
+	Border        *DefinitionBorder         `yaml:"Border"`
+	HeaderAlign   string                    `yaml:"HeaderAlign"`
+	Ports         map[string]DefinitionPort `yaml:"Ports"`
+	Directory     DefinitionDirectory       `yaml:"Directory"`
+	ZipFile       DefinitionZipFile         `yaml:"ZipFile"`
+	CFn           DefinitionCFn             `yaml:"CFn"`
 	Parent        *Definition
 	CacheFilePath string
 }
@@ -26,12 +27,36 @@ type DefinitionLabel struct {
 }
 
 type DefinitionFill struct {
-	Color string `yaml:"Color"`
+	Type  string  `yaml:"Type"` // solid(default) / linear-gradient
+	Color string  `yaml:"Color"`
+	From  string  `yaml:"From"`
+	To    string  `yaml:"To"`
+	Angle float64 `yaml:"Angle"`
 }
 
 type DefinitionBorder struct {
-	Color string `yaml:"Color"`
-	Type  string `yaml:"Type"`
+	Color        string            `yaml:"Color"`
+	Type         string            `yaml:"Type"`
+	Width        int               `yaml:"Width"`
+	DashPattern  []float64         `yaml:"DashPattern"`
+	CornerRadius int               `yaml:"CornerRadius"`
+	Shadow       *DefinitionShadow `yaml:"Shadow"`
+}
+
+type DefinitionShadow struct {
+	Offset DefinitionOffset `yaml:"Offset"`
+	Blur   int              `yaml:"Blur"`
+	Color  string           `yaml:"Color"`
+}
+
+type DefinitionOffset struct {
+	X int `yaml:"X"`
+	Y int `yaml:"Y"`
+}
+
+type DefinitionPort struct {
+	Side string   `yaml:"Side"`
+	At   *float64 `yaml:"At"`
 }
 
 // [TODO] make interface
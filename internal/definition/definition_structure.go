@@ -15,6 +15,7 @@ import (
 
 type DefinitionStructure struct {
 	Definitions map[string]*Definition `yaml:"Definitions"`
+	Colors      map[string]string      `yaml:"Colors"` // palette referred to as $name in color fields
 }
 
 func (ds *DefinitionStructure) LoadDefinitions(filePath string) error {
@@ -145,5 +146,9 @@ func (ds *DefinitionStructure) LoadDefinitions(filePath string) error {
 		ds.Definitions = map[string]*Definition{}
 	}
 	maps.Copy(ds.Definitions, b.Definitions)
+	if ds.Colors == nil {
+		ds.Colors = map[string]string{}
+	}
+	maps.Copy(ds.Colors, b.Colors)
 	return nil
 }
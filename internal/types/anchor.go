@@ -0,0 +1,144 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package types
+
+import (
+	"fmt"
+	"image"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Anchor is a point anywhere on a side of a resource. At is the fraction of the side from its
+// north or west end, so E@0.3 is 30% down the east side.
+type Anchor struct {
+	Side Windrose
+	At   float64
+}
+
+// ConvertAnchor converts a fractional position such as E@0.3
+func ConvertAnchor(position string) (Anchor, error) {
+	side, at, ok := strings.Cut(position, "@")
+	if !ok {
+		return Anchor{}, fmt.Errorf("unknown anchor: %s, anchors are written as <side>@<fraction>, e.g. E@0.3", position)
+	}
+	fraction, err := strconv.ParseFloat(at, 64)
+	if err != nil || fraction < 0 || fraction > 1 {
+		return Anchor{}, fmt.Errorf("unknown anchor: %s, the fraction must be between 0 and 1", position)
+	}
+	return NewAnchor(side, fraction)
+}
+
+// NewAnchor returns the anchor at the fraction at of the side N, E, S or W
+func NewAnchor(side string, at float64) (Anchor, error) {
+	if at < 0 || at > 1 {
+		return Anchor{}, fmt.Errorf("anchor fraction %v is out of range, it must be between 0 and 1", at)
+	}
+	switch side {
+	case "N":
+		return Anchor{Side: WINDROSE_N, At: at}, nil
+	case "E":
+		return Anchor{Side: WINDROSE_E, At: at}, nil
+	case "S":
+		return Anchor{Side: WINDROSE_S, At: at}, nil
+	case "W":
+		return Anchor{Side: WINDROSE_W, At: at}, nil
+	}
+	return Anchor{}, fmt.Errorf("unknown anchor side: %s, supported sides are N, E, S, W", side)
+}
+
+func (a Anchor) String() string {
+	return fmt.Sprintf("%s@%g", a.Side, a.At)
+}
+
+// point returns the anchor on the bindings of its resource
+func (a Anchor) point(bindings image.Rectangle) image.Point {
+	x := bindings.Min.X + int(math.Round(float64(bindings.Dx())*a.At))
+	y := bindings.Min.Y + int(math.Round(float64(bindings.Dy())*a.At))
+	switch a.Side {
+	case WINDROSE_N:
+		return image.Point{x, bindings.Min.Y}
+	case WINDROSE_E:
+		return image.Point{bindings.Max.X, y}
+	case WINDROSE_S:
+		return image.Point{x, bindings.Max.Y}
+	default:
+		return image.Point{bindings.Min.X, y}
+	}
+}
+
+// SetPort declares a named anchor links can attach to
+func (r *Resource) SetPort(name string, anchor Anchor) {
+	if r.ports == nil {
+		r.ports = map[string]Anchor{}
+	}
+	r.ports[name] = anchor
+}
+
+func (r *Resource) GetPort(name string) (Anchor, bool) {
+	anchor, ok := r.ports[name]
+	return anchor, ok
+}
+
+// SetSourceAnchor attaches the source end of the link to the anchor instead of a windrose point
+func (l *Link) SetSourceAnchor(anchor Anchor) {
+	l.sourceAnchor = &anchor
+	l.SourcePosition = anchor.Side
+}
+
+// SetTargetAnchor attaches the target end of the link to the anchor instead of a windrose point
+func (l *Link) SetTargetAnchor(anchor Anchor) {
+	l.targetAnchor = &anchor
+	l.TargetPosition = anchor.Side
+}
+
+// GetSourceAnchor returns the anchor of the source end, nil for a windrose point
+func (l *Link) GetSourceAnchor() *Anchor {
+	return l.sourceAnchor
+}
+
+// GetTargetAnchor returns the anchor of the target end, nil for a windrose point
+func (l *Link) GetTargetAnchor() *Anchor {
+	return l.targetAnchor
+}
+
+// anchorOn returns the anchor of the end of the link on the resource, nil for a windrose point
+func (l *Link) anchorOn(resource *Resource) *Anchor {
+	if l.Source == resource {
+		return l.sourceAnchor
+	}
+	if l.Target == resource {
+		return l.targetAnchor
+	}
+	return nil
+}
+
+// positionKey identifies the point where the link attaches to the resource. Links with the same
+// key share the point and are spread around it.
+func (l *Link) positionKey(resource *Resource) string {
+	if anchor := l.anchorOn(resource); anchor != nil {
+		return fmt.Sprintf("%d@%g", anchor.Side, anchor.At)
+	}
+	if l.Source == resource {
+		return fmt.Sprintf("%d", l.SourcePosition)
+	}
+	if l.Target == resource {
+		return fmt.Sprintf("%d", l.TargetPosition)
+	}
+	return ""
+}
+
+// endPoint returns the point of the source or the target end of the link, before spreading
+func (l *Link) endPoint(isSource bool) image.Point {
+	resource, position, anchor := l.Target, l.TargetPosition, l.targetAnchor
+	if isSource {
+		resource, position, anchor = l.Source, l.SourcePosition, l.sourceAnchor
+	}
+	if anchor != nil {
+		return anchor.point(resource.GetBindings())
+	}
+	pt, _ := calcPosition(resource.GetBindings(), position)
+	return pt
+}
@@ -0,0 +1,71 @@
+package types
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+func TestConvertAnchor(t *testing.T) {
+	anchor, err := ConvertAnchor("E@0.3")
+	if err != nil {
+		t.Fatalf("ConvertAnchor failed: %v", err)
+	}
+	if anchor != (Anchor{Side: WINDROSE_E, At: 0.3}) {
+		t.Errorf("expected E@0.3, got %v", anchor)
+	}
+	for _, position := range []string{"E", "NE@0.5", "E@1.5", "E@x"} {
+		if _, err := ConvertAnchor(position); err == nil {
+			t.Errorf("expected error for %s", position)
+		}
+	}
+}
+
+func TestAnchorPoint(t *testing.T) {
+	bindings := image.Rect(100, 100, 300, 200)
+	for _, tt := range []struct {
+		anchor   Anchor
+		expected image.Point
+	}{
+		{Anchor{Side: WINDROSE_E, At: 0.3}, image.Pt(300, 130)},
+		{Anchor{Side: WINDROSE_W, At: 0}, image.Pt(100, 100)},
+		{Anchor{Side: WINDROSE_N, At: 0.25}, image.Pt(150, 100)},
+		{Anchor{Side: WINDROSE_S, At: 1}, image.Pt(300, 200)},
+	} {
+		if pt := tt.anchor.point(bindings); pt != tt.expected {
+			t.Errorf("%v: expected %v, got %v", tt.anchor, tt.expected, pt)
+		}
+	}
+}
+
+func TestLinkIndexAndCountPerPort(t *testing.T) {
+	vpc := new(Resource).Init()
+	vpc.SetBindings(image.Rect(0, 0, 400, 400))
+	vpc.SetGroupingOffset(true)
+	newLink := func(anchor *Anchor) *Link {
+		source := new(Resource).Init()
+		source.SetBindings(image.Rect(-200, 0, -136, 64))
+		link := Link{}.Init(source, WINDROSE_E, ArrowHead{}, vpc, WINDROSE_W, ArrowHead{}, 2, color.RGBA{0, 0, 0, 255})
+		if anchor != nil {
+			link.SetTargetAnchor(*anchor)
+		}
+		vpc.AddLink(link)
+		return link
+	}
+	ingress := Anchor{Side: WINDROSE_W, At: 0.2}
+	plain := newLink(nil)
+	first := newLink(&ingress)
+	second := newLink(&ingress)
+
+	// Links are spread per port, apart from the links at the windrose position of the side
+	if index, count := second.getLinkIndexAndCount(vpc, WINDROSE_W); index != 1 || count != 2 {
+		t.Errorf("expected index 1 of 2 at the port, got %d of %d", index, count)
+	}
+	if _, count := plain.getLinkIndexAndCount(vpc, WINDROSE_W); count != 1 {
+		t.Errorf("expected the plain link alone at W, got %d links", count)
+	}
+	pt := first.calcPositionWithOffset(vpc.GetBindings(), WINDROSE_W, vpc, false)
+	if pt.X != 0 || pt.Y == 80 {
+		t.Errorf("expected the first link spread around (0, 80), got %v", pt)
+	}
+}
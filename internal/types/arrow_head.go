@@ -0,0 +1,101 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package types
+
+import (
+	"image"
+
+	"github.com/awslabs/diagram-as-code/internal/vector"
+)
+
+// arrowHeadLength returns the length of the arrow head. By default, Default and Open arrow heads
+// are 10 pixels long as they always were, and the other shapes grow with thick lines.
+func (l *Link) arrowHeadLength(arrowHead ArrowHead) float64 {
+	if arrowHead.Length != 0 {
+		return arrowHead.Length
+	}
+	switch arrowHead.Type {
+	case "", "Default", "Open":
+		return 10
+	}
+	return 5 * float64(maxInt(2, l.LineWidth))
+}
+
+// arrowHeadSize returns the depth of the arrow head along the link and its half width
+func (l *Link) arrowHeadSize(arrowHead ArrowHead) (float64, float64) {
+	length := l.arrowHeadLength(arrowHead)
+	a, b, c := l.getThreeSide(arrowHead.Width)
+	if b == 0 {
+		a, b, c = l.getThreeSide("Default")
+	}
+	return length * a / b, length * c / b
+}
+
+// arrowHeadGaps returns the parts of the link, as distances from its end, hidden by an open
+// arrow head so that the line doesn't run through it
+func (l *Link) arrowHeadGaps(arrowHead ArrowHead) [][2]float64 {
+	depth, half := l.arrowHeadSize(arrowHead)
+	switch arrowHead.Type {
+	case "OpenDiamond":
+		return [][2]float64{{0, 2 * depth}}
+	case "OpenCircle":
+		return [][2]float64{{0, 2 * half}}
+	case "ZeroOrMany":
+		return [][2]float64{{depth * 1.5, depth*1.5 + half}}
+	}
+	return nil
+}
+
+// arrowHeadParts returns the parts of a path of the given length hidden by its open arrow heads,
+// as distances from its start
+func (l *Link) arrowHeadParts(length float64) [][2]float64 {
+	hidden := l.arrowHeadGaps(l.SourceArrowHead)
+	for _, gap := range l.arrowHeadGaps(l.TargetArrowHead) {
+		hidden = append(hidden, [2]float64{length - gap[1], length - gap[0]})
+	}
+	return hidden
+}
+
+// drawArrowShape draws the arrow heads other than Default and Open. back is the unit vector from
+// the tip along the link.
+func (l *Link) drawArrowShape(img *image.RGBA, tip, back vector.Vector, arrowHead ArrowHead) {
+	depth, half := l.arrowHeadSize(arrowHead)
+	side := back.Perpendicular()
+	at := func(x, y float64) vector.Vector {
+		return tip.Add(back.Scale(x)).Add(side.Scale(y))
+	}
+
+	width := float64(l.LineWidth)
+	stroke := func(closed bool, pts ...vector.Vector) {
+		l.strokeOutline(img, pts, closed)
+	}
+	fill := func(pts ...vector.Vector) {
+		fillPolygons(img, []polygon{polygon(pts).clockwise()}, l.lineColor)
+	}
+
+	switch arrowHead.Type {
+	case "Diamond":
+		fill(at(0, 0), at(depth, half), at(2*depth, 0), at(depth, -half))
+	case "OpenDiamond":
+		stroke(true, at(0, 0), at(depth, half), at(2*depth, 0), at(depth, -half))
+	case "Circle":
+		fillPolygons(img, []polygon{circlePolygon(at(half, 0), half)}, l.lineColor)
+	case "OpenCircle":
+		fillPolygons(img, ringPolygons(at(half, 0), half, width), l.lineColor)
+	case "Bar":
+		stroke(false, at(depth, -half), at(depth, half))
+	case "Many", "OneOrMany", "ZeroOrMany":
+		// The crow's foot spreads to the resource
+		stroke(false, at(0, half), at(depth, 0), at(0, -half))
+		if arrowHead.Type == "OneOrMany" {
+			stroke(false, at(depth*1.5, -half), at(depth*1.5, half))
+		}
+		if arrowHead.Type == "ZeroOrMany" {
+			fillPolygons(img, ringPolygons(at(depth*1.5+half/2, 0), half/2, width), l.lineColor)
+		}
+	case "Double":
+		fill(at(0, 0), at(depth, half), at(depth, -half))
+		fill(at(depth, 0), at(2*depth, half), at(2*depth, -half))
+	}
+}
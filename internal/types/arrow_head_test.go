@@ -0,0 +1,59 @@
+package types
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+func TestArrowHeadLength(t *testing.T) {
+	for _, tt := range []struct {
+		lineWidth int
+		arrowHead ArrowHead
+		expected  float64
+	}{
+		{1, ArrowHead{Type: "Diamond"}, 10},
+		{2, ArrowHead{Type: "Diamond"}, 10},
+		{4, ArrowHead{Type: "Diamond"}, 20},
+		{4, ArrowHead{Type: "Diamond", Length: 12}, 12},
+		// Default and Open arrow heads keep their length on thick lines
+		{4, ArrowHead{Type: "Default"}, 10},
+		{4, ArrowHead{Type: "Open"}, 10},
+		{8, ArrowHead{}, 10},
+		{8, ArrowHead{Type: "Open", Length: 16}, 16},
+	} {
+		link := Link{}.Init(new(Resource).Init(), WINDROSE_E, ArrowHead{}, new(Resource).Init(), WINDROSE_W, ArrowHead{}, tt.lineWidth, color.RGBA{0, 0, 0, 255})
+		if length := link.arrowHeadLength(tt.arrowHead); length != tt.expected {
+			t.Errorf("LineWidth %d, Length %v: expected %v, got %v", tt.lineWidth, tt.arrowHead.Length, tt.expected, length)
+		}
+	}
+}
+
+func TestDrawArrowShapes(t *testing.T) {
+	for _, tt := range []struct {
+		arrowHead string
+		filled    bool // whether the middle of the head is drawn
+	}{
+		{"Diamond", true},
+		{"OpenDiamond", false},
+		{"Circle", true},
+		{"OpenCircle", false},
+		{"Double", true},
+	} {
+		arrowHead := ArrowHead{Type: tt.arrowHead, Length: 20}
+		link := Link{}.Init(new(Resource).Init(), WINDROSE_E, ArrowHead{}, new(Resource).Init(), WINDROSE_W, arrowHead, 2, color.RGBA{0, 0, 0, 255})
+		link.path = &linkPath{sourcePt: image.Pt(10, 50), targetPt: image.Pt(90, 50)}
+		img := image.NewRGBA(image.Rect(0, 0, 100, 100))
+		if err := link.drawPolyline(img, link.path); err != nil {
+			t.Fatalf("%s: drawPolyline failed: %v", tt.arrowHead, err)
+		}
+
+		// The heads are about 14px deep, the middle of the first one is 7px from the end
+		if filled := img.RGBAAt(83, 50).A != 0; filled != tt.filled {
+			t.Errorf("%s: expected the middle of the head filled %v, got %v", tt.arrowHead, tt.filled, filled)
+		}
+		if img.RGBAAt(50, 50).A == 0 {
+			t.Errorf("%s: expected the line drawn", tt.arrowHead)
+		}
+	}
+}
@@ -0,0 +1,151 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package types
+
+import (
+	"image"
+	"math"
+
+	log "github.com/sirupsen/logrus"
+)
+
+func (l *Link) SetBundle(bundle string) {
+	l.bundle = bundle
+}
+
+// bundleLinks merges the links of each bundle into a trunk from the resource they share, which
+// splits into short branches to the other ends. It runs once the paths of all links are known.
+func bundleLinks(links []*Link) {
+	bundles := map[string][]*Link{}
+	names := []string{}
+	for _, link := range links {
+		if link.bundle == "" {
+			continue
+		}
+		if link.Type == "orthogonal-routed" {
+			// A shared trunk would go through the resources the route goes around
+			log.Warnf("Routed link from %s to %s in bundle %s is not bundled, to keep its route", link.Source.label, link.Target.label, link.bundle)
+			continue
+		}
+		if _, ok := bundles[link.bundle]; !ok {
+			names = append(names, link.bundle)
+		}
+		bundles[link.bundle] = append(bundles[link.bundle], link)
+	}
+	for _, name := range names {
+		members := bundles[name]
+		if len(members) < 2 {
+			continue
+		}
+		sharedSource, sharedTarget := true, true
+		for _, link := range members {
+			sharedSource = sharedSource && link.Source == members[0].Source
+			sharedTarget = sharedTarget && link.Target == members[0].Target
+		}
+		if !sharedSource && !sharedTarget {
+			log.Warnf("Links of bundle %s share neither their source nor their target, not bundled", name)
+			continue
+		}
+		bundle(name, members, sharedSource)
+	}
+}
+
+// bundle rebuilds the paths of the links from the hub, their shared source or target
+func bundle(name string, members []*Link, fromSource bool) {
+	first := members[0]
+	hubPosition := first.TargetPosition
+	if fromSource {
+		hubPosition = first.SourcePosition
+	}
+	hubPt := first.endPoint(fromSource)
+	dirVec := first.getDirectionVector(int(hubPosition))
+	dir := image.Point{int(dirVec.X), int(dirVec.Y)}
+	along := func(p image.Point) int {
+		d := p.Sub(hubPt)
+		return d.X*dir.X + d.Y*dir.Y
+	}
+
+	// The end of each branch, and the point where orthogonal branches leave the bus
+	branchPts := []image.Point{}
+	anchors := []image.Point{}
+	for _, link := range members {
+		branchPt, branchPosition := link.path.sourcePt, link.SourcePosition
+		if fromSource {
+			branchPt, branchPosition = link.path.targetPt, link.TargetPosition
+		}
+		branchDir := link.getDirectionVector(int(branchPosition))
+		anchor := branchPt
+		if int(branchDir.X)*dir.X+int(branchDir.Y)*dir.Y == 0 {
+			anchor = branchPt.Add(image.Point{int(branchDir.X), int(branchDir.Y)}.Mul(ROUTE_STUB))
+		}
+		branchPts = append(branchPts, branchPt)
+		anchors = append(anchors, anchor)
+	}
+
+	// The trunk ends halfway to the nearest branch
+	nearest := 0
+	for i, anchor := range anchors {
+		if i == 0 || along(anchor) < nearest {
+			nearest = along(anchor)
+		}
+	}
+	junction := hubPt.Add(dir.Mul(maxInt(ROUTE_STUB, nearest/2)))
+
+	// Segments already drawn by a member, from the hub, to draw the trunk and the bus only once
+	drawn := [][2]image.Point{}
+	for i, link := range members {
+		if link.path.curves != nil || len(link.waypoints) != 0 {
+			log.Warnf("Curved link or link with waypoints in bundle %s is not bundled", name)
+			continue
+		}
+		pts := []image.Point{hubPt, junction}
+		if link.Type == "orthogonal" {
+			// Along the bus through the junction, perpendicular to the trunk
+			bus := anchors[i].Sub(dir.Mul(along(anchors[i]) - along(junction)))
+			pts = append(pts, bus, anchors[i])
+		}
+		pts = simplifyPath(append(pts, branchPts[i]))
+		if len(pts) < 2 {
+			continue
+		}
+		covered := map[int][2]float64{}
+		for j := 0; j+1 < len(pts); j++ {
+			length := segmentLength(pts[j], pts[j+1])
+			for _, d := range drawn {
+				if d[0] == pts[j] && sameDirection(d[0], d[1], pts[j], pts[j+1]) {
+					covered[j] = [2]float64{0, math.Min(segmentLength(d[0], d[1]), length)}
+				}
+			}
+		}
+		for j := 0; j+1 < len(pts); j++ {
+			drawn = append(drawn, [2]image.Point{pts[j], pts[j+1]})
+		}
+		if !fromSource {
+			for a, b := 0, len(pts)-1; a < b; a, b = a+1, b-1 {
+				pts[a], pts[b] = pts[b], pts[a]
+			}
+			reversed := map[int][2]float64{}
+			for j, c := range covered {
+				length := segmentLength(pts[len(pts)-2-j], pts[len(pts)-1-j])
+				reversed[len(pts)-2-j] = [2]float64{length - c[1], length - c[0]}
+			}
+			covered = reversed
+		}
+		link.path.sourcePt = pts[0]
+		link.path.targetPt = pts[len(pts)-1]
+		link.path.controlPts = pts[1 : len(pts)-1]
+		link.path.covered = covered
+	}
+}
+
+func segmentLength(p1, p2 image.Point) float64 {
+	return toVector(p2).Sub(toVector(p1)).Length()
+}
+
+// sameDirection reports whether the segments a1-a2 and b1-b2 head the same way
+func sameDirection(a1, a2, b1, b2 image.Point) bool {
+	a := a2.Sub(a1)
+	b := b2.Sub(b1)
+	return a.X*b.Y == a.Y*b.X && a.X*b.X+a.Y*b.Y > 0
+}
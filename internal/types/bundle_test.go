@@ -0,0 +1,107 @@
+package types
+
+import (
+	"image"
+	"image/color"
+	"reflect"
+	"testing"
+)
+
+func TestBundleLinks(t *testing.T) {
+	newResource := func(bindings image.Rectangle) *Resource {
+		r := new(Resource).Init()
+		r.SetBindings(bindings)
+		return r
+	}
+	newLink := func(linkType string, source *Resource, sourcePosition Windrose, target *Resource, targetPosition Windrose) *Link {
+		link := Link{}.Init(source, sourcePosition, ArrowHead{}, target, targetPosition, ArrowHead{}, 2, color.RGBA{0, 0, 0, 255})
+		link.SetType(linkType)
+		link.SetBundle("web")
+		sourcePt, _ := calcPosition(source.GetBindings(), sourcePosition)
+		targetPt, _ := calcPosition(target.GetBindings(), targetPosition)
+		link.path = &linkPath{sourcePt: sourcePt, targetPt: targetPt}
+		return link
+	}
+	hub := newResource(image.Rect(100, 0, 164, 64))
+	left := newResource(image.Rect(0, 200, 64, 264))
+	right := newResource(image.Rect(200, 200, 264, 264))
+
+	// Orthogonal links from a shared source run along a bus halfway to the branches
+	links := []*Link{
+		newLink("orthogonal", hub, WINDROSE_S, left, WINDROSE_N),
+		newLink("orthogonal", hub, WINDROSE_S, right, WINDROSE_N),
+	}
+	bundleLinks(links)
+	expected := [][]image.Point{
+		{{132, 64}, {132, 132}, {32, 132}, {32, 200}},
+		{{132, 64}, {132, 132}, {232, 132}, {232, 200}},
+	}
+	for i, link := range links {
+		if pts := link.path.points(); !reflect.DeepEqual(pts, expected[i]) {
+			t.Errorf("orthogonal link %d: expected %v, got %v", i, expected[i], pts)
+		}
+	}
+	if len(links[0].path.covered) != 0 {
+		t.Errorf("expected the first link to draw the trunk, got %v", links[0].path.covered)
+	}
+	if c := links[1].path.covered; !reflect.DeepEqual(c, map[int][2]float64{0: {0, 68}}) {
+		t.Errorf("expected the trunk of the second link to be covered, got %v", c)
+	}
+
+	// Straight links into a shared target meet at the junction
+	links = []*Link{
+		newLink("straight", left, WINDROSE_N, hub, WINDROSE_S),
+		newLink("straight", right, WINDROSE_N, hub, WINDROSE_S),
+	}
+	bundleLinks(links)
+	expected = [][]image.Point{
+		{{32, 200}, {132, 132}, {132, 64}},
+		{{232, 200}, {132, 132}, {132, 64}},
+	}
+	for i, link := range links {
+		if pts := link.path.points(); !reflect.DeepEqual(pts, expected[i]) {
+			t.Errorf("straight link %d: expected %v, got %v", i, expected[i], pts)
+		}
+	}
+	if c := links[1].path.covered; !reflect.DeepEqual(c, map[int][2]float64{1: {0, 68}}) {
+		t.Errorf("expected the trunk at the end of the second link to be covered, got %v", c)
+	}
+
+	// Links sharing no end are left alone
+	links = []*Link{
+		newLink("straight", hub, WINDROSE_S, left, WINDROSE_N),
+		newLink("straight", left, WINDROSE_E, right, WINDROSE_W),
+	}
+	bundleLinks(links)
+	for i, link := range links {
+		if len(link.path.controlPts) != 0 {
+			t.Errorf("link %d: expected no bundling, got %v", i, link.path.points())
+		}
+	}
+
+	// Routed links keep the route around the obstacle between the hub and the branches
+	obstacle := image.Rect(100, 100, 164, 164)
+	routes := [][]image.Point{
+		{{132, 84}, {80, 84}, {80, 180}, {32, 180}},
+		{{132, 84}, {184, 84}, {184, 180}, {232, 180}},
+	}
+	links = []*Link{
+		newLink("orthogonal-routed", hub, WINDROSE_S, left, WINDROSE_N),
+		newLink("orthogonal-routed", hub, WINDROSE_S, right, WINDROSE_N),
+	}
+	for i, link := range links {
+		link.path.controlPts = routes[i]
+	}
+	bundleLinks(links)
+	for i, link := range links {
+		if !reflect.DeepEqual(link.path.controlPts, routes[i]) || len(link.path.covered) != 0 {
+			t.Errorf("routed link %d: expected the route to be kept, got %v", i, link.path.points())
+		}
+		for j, s := range link.path.segments() {
+			segment := image.Rect(int(s[0].X), int(s[0].Y), int(s[1].X), int(s[1].Y)).Canon()
+			if segment.Overlaps(obstacle) {
+				t.Errorf("routed link %d: segment %d crosses the obstacle", i, j)
+			}
+		}
+	}
+}
@@ -0,0 +1,106 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package types
+
+import (
+	"image"
+	"math"
+
+	"github.com/awslabs/diagram-as-code/internal/vector"
+)
+
+const (
+	CURVE_TENSION    = 0.4 // length of the tangents relative to the distance between the ends
+	CURVE_MIN_HANDLE = 30  // minimum length of the tangents
+)
+
+// curveControlPoints returns the control points of a curved link. The tangents leave the source
+// and enter the target in the directions of SourcePosition and TargetPosition.
+func (l *Link) curveControlPoints(sourcePt, targetPt image.Point) (vector.Vector, vector.Vector) {
+	sourceVec := vector.New(float64(sourcePt.X), float64(sourcePt.Y))
+	targetVec := vector.New(float64(targetPt.X), float64(targetPt.Y))
+	handle := math.Max(targetVec.Sub(sourceVec).Length()*CURVE_TENSION, CURVE_MIN_HANDLE)
+	c1 := sourceVec.Add(l.getDirectionVector(int(l.SourcePosition)).Scale(handle))
+	c2 := targetVec.Add(l.getDirectionVector(int(l.TargetPosition)).Scale(handle))
+	return c1, c2
+}
+
+// curveSegments returns the control points of the curves from the source to the target through
+// the waypoints. At a waypoint the curve runs parallel to the line between its neighbors.
+func (l *Link) curveSegments(sourcePt, targetPt image.Point) [][4]vector.Vector {
+	waypoints := l.waypointPoints()
+	if len(waypoints) == 0 {
+		c1, c2 := l.curveControlPoints(sourcePt, targetPt)
+		return [][4]vector.Vector{{toVector(sourcePt), c1, c2, toVector(targetPt)}}
+	}
+
+	pts := []vector.Vector{toVector(sourcePt)}
+	for _, p := range waypoints {
+		pts = append(pts, toVector(p))
+	}
+	pts = append(pts, toVector(targetPt))
+	// Directions of the curve at every point, the target one pointing out of the target
+	directions := []vector.Vector{l.getDirectionVector(int(l.SourcePosition))}
+	for i := 1; i+1 < len(pts); i++ {
+		directions = append(directions, pts[i+1].Sub(pts[i-1]).Normalize())
+	}
+	directions = append(directions, l.getDirectionVector(int(l.TargetPosition)).Scale(-1))
+
+	segments := [][4]vector.Vector{}
+	for i := 0; i+1 < len(pts); i++ {
+		handle := pts[i+1].Sub(pts[i]).Length() * CURVE_TENSION
+		sourceHandle, targetHandle := handle, handle
+		if i == 0 {
+			sourceHandle = math.Max(handle, CURVE_MIN_HANDLE)
+		}
+		if i+2 == len(pts) {
+			targetHandle = math.Max(handle, CURVE_MIN_HANDLE)
+		}
+		segments = append(segments, [4]vector.Vector{
+			pts[i],
+			pts[i].Add(directions[i].Scale(sourceHandle)),
+			pts[i+1].Sub(directions[i+1].Scale(targetHandle)),
+			pts[i+1],
+		})
+	}
+	return segments
+}
+
+func toVector(p image.Point) vector.Vector {
+	return vector.New(float64(p.X), float64(p.Y))
+}
+
+// cubicBezier returns the point of the curve at t (0 <= t <= 1)
+func cubicBezier(p0, p1, p2, p3 vector.Vector, t float64) vector.Vector {
+	u := 1 - t
+	return p0.Scale(u * u * u).
+		Add(p1.Scale(3 * u * u * t)).
+		Add(p2.Scale(3 * u * t * t)).
+		Add(p3.Scale(t * t * t))
+}
+
+// cubicBezierTangent returns the derivative of the curve at t
+func cubicBezierTangent(p0, p1, p2, p3 vector.Vector, t float64) vector.Vector {
+	u := 1 - t
+	return p1.Sub(p0).Scale(3 * u * u).
+		Add(p2.Sub(p1).Scale(6 * u * t)).
+		Add(p3.Sub(p2).Scale(3 * t * t))
+}
+
+// flattenCurves returns the curves as a polyline with segments about two pixels long
+func flattenCurves(curves [][4]vector.Vector) []vector.Vector {
+	pts := []vector.Vector{}
+	for _, c := range curves {
+		// The control polygon is longer than the curve
+		polygon := c[1].Sub(c[0]).Length() + c[2].Sub(c[1]).Length() + c[3].Sub(c[2]).Length()
+		steps := maxInt(8, int(math.Ceil(polygon/2)))
+		for s := 0; s <= steps; s++ {
+			if s == 0 && len(pts) != 0 {
+				continue
+			}
+			pts = append(pts, cubicBezier(c[0], c[1], c[2], c[3], float64(s)/float64(steps)))
+		}
+	}
+	return pts
+}
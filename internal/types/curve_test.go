@@ -0,0 +1,59 @@
+package types
+
+import (
+	"image"
+	"image/color"
+	"testing"
+
+	"github.com/awslabs/diagram-as-code/internal/vector"
+)
+
+func TestCubicBezier(t *testing.T) {
+	p0, p1, p2, p3 := vector.New(0, 0), vector.New(0, -40), vector.New(100, -40), vector.New(100, 0)
+	if p := cubicBezier(p0, p1, p2, p3, 0); p != p0 {
+		t.Errorf("expected the curve to start at %v, got %v", p0, p)
+	}
+	if p := cubicBezier(p0, p1, p2, p3, 1); p != p3 {
+		t.Errorf("expected the curve to end at %v, got %v", p3, p)
+	}
+	if p := cubicBezier(p0, p1, p2, p3, 0.5); p != vector.New(50, -30) {
+		t.Errorf("expected the middle of the curve at (50,-30), got %v", p)
+	}
+	if d := cubicBezierTangent(p0, p1, p2, p3, 0).Normalize(); d != vector.New(0, -1) {
+		t.Errorf("expected the curve to leave to the north, got %v", d)
+	}
+}
+
+func TestCurveControlPoints(t *testing.T) {
+	source := new(Resource).Init()
+	target := new(Resource).Init()
+	link := Link{}.Init(source, WINDROSE_E, ArrowHead{}, target, WINDROSE_N, ArrowHead{}, 2, color.RGBA{0, 0, 0, 255})
+	c1, c2 := link.curveControlPoints(image.Pt(0, 0), image.Pt(200, 100))
+	if c1.Y != 0 || c1.X <= 0 {
+		t.Errorf("expected the source tangent to the east, got %v", c1)
+	}
+	if c2.X != 200 || c2.Y >= 100 {
+		t.Errorf("expected the target tangent from the north, got %v", c2)
+	}
+
+	// Close ends keep a minimum tangent
+	c1, _ = link.curveControlPoints(image.Pt(0, 0), image.Pt(10, 0))
+	if c1.X != CURVE_MIN_HANDLE {
+		t.Errorf("expected a tangent of %d, got %v", CURVE_MIN_HANDLE, c1)
+	}
+}
+
+func TestDrawCurve(t *testing.T) {
+	link := Link{}.Init(new(Resource).Init(), WINDROSE_N, ArrowHead{}, new(Resource).Init(), WINDROSE_N, ArrowHead{}, 1, color.RGBA{0, 0, 0, 255})
+	img := image.NewRGBA(image.Rect(0, 0, 120, 60))
+	curves := [][4]vector.Vector{{vector.New(10, 50), vector.New(10, 10), vector.New(110, 10), vector.New(110, 50)}}
+	link.drawPath(img, flattenCurves(curves), nil)
+	for _, p := range []image.Point{{10, 50}, {60, 20}, {110, 50}} {
+		if img.RGBAAt(p.X, p.Y).A == 0 {
+			t.Errorf("expected the curve to pass through %v", p)
+		}
+	}
+	if img.RGBAAt(60, 50).A != 0 {
+		t.Error("expected nothing drawn below the curve")
+	}
+}
@@ -0,0 +1,104 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package types
+
+import (
+	"math"
+
+	"github.com/awslabs/diagram-as-code/internal/vector"
+)
+
+// dashPattern returns the lengths of the dashes and the gaps between them for a line style, nil
+// for a solid line. Dots are as long as the line is wide.
+func dashPattern(style string, width int) []float64 {
+	dot := float64(maxInt(1, width))
+	switch style {
+	case "dashed":
+		return []float64{6, 3}
+	case "dotted":
+		return []float64{dot, 2 * dot}
+	case "dash-dot":
+		return []float64{6, 3, dot, 3}
+	}
+	return nil
+}
+
+// dashPolyline splits the polyline into its dashes, the pattern starting at phase
+func dashPolyline(pts []vector.Vector, pattern []float64, phase float64) [][]vector.Vector {
+	if len(pattern) == 0 {
+		return [][]vector.Vector{pts}
+	}
+	total := polylineLength(pts)
+	dashes := [][]vector.Vector{}
+	for _, part := range dashIntervals(pattern, phase, total) {
+		dashes = append(dashes, subPolyline(pts, part[0], part[1]))
+	}
+	return dashes
+}
+
+// dashIntervals returns the dashes of the pattern between 0 and length, the pattern starting at
+// phase
+func dashIntervals(pattern []float64, phase, length float64) [][2]float64 {
+	period := 0.0
+	for _, l := range pattern {
+		period += l
+	}
+	if period <= 0 {
+		return [][2]float64{{0, length}}
+	}
+	intervals := [][2]float64{}
+	start := -math.Mod(phase, period)
+	if start > 0 {
+		start -= period
+	}
+	for ; start < length; start += period {
+		d := start
+		for i, l := range pattern {
+			if i%2 == 0 {
+				a, b := math.Max(d, 0), math.Min(d+l, length)
+				if b > a {
+					intervals = append(intervals, [2]float64{a, b})
+				}
+			}
+			d += l
+		}
+	}
+	return intervals
+}
+
+func (l *Link) SetDashPattern(pattern []float64) {
+	l.dashPattern = pattern
+}
+
+// linePattern returns the dash pattern of the link, the custom one over the one of LineStyle
+func (l *Link) linePattern() []float64 {
+	if len(l.dashPattern) != 0 {
+		return l.dashPattern
+	}
+	return dashPattern(l.LineStyle, l.LineWidth)
+}
+
+func (r *Resource) SetBorderWidth(width int) {
+	r.borderWidth = width
+}
+
+func (r *Resource) SetBorderDashPattern(pattern []float64) {
+	r.borderDashes = pattern
+}
+
+// borderPattern returns the dash pattern of the border, the custom one over the one of its type
+func (r *Resource) borderPattern() []float64 {
+	if len(r.borderDashes) != 0 {
+		return r.borderDashes
+	}
+	switch r.borderType {
+	case BORDER_TYPE_DASHED:
+		return dashPattern("dashed", r.borderWidth)
+	case BORDER_TYPE_DOTTED:
+		return dashPattern("dotted", r.borderWidth)
+	case BORDER_TYPE_DASH_DOT:
+		return dashPattern("dash-dot", r.borderWidth)
+	}
+	return nil
+}
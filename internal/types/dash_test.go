@@ -0,0 +1,41 @@
+package types
+
+import (
+	"image"
+	"image/color"
+	"reflect"
+	"testing"
+)
+
+func TestDashIntervals(t *testing.T) {
+	pattern := dashPattern("dash-dot", 2)
+	expected := [][2]float64{{0, 6}, {9, 11}, {14, 20}, {23, 25}}
+	if intervals := dashIntervals(pattern, 0, 25); !reflect.DeepEqual(intervals, expected) {
+		t.Errorf("expected dashes %v, got %v", expected, intervals)
+	}
+	// The pattern starts at the phase, here in the gap after the dash
+	expected = [][2]float64{{1, 3}, {6, 12}}
+	if intervals := dashIntervals(pattern, 8, 12); !reflect.DeepEqual(intervals, expected) {
+		t.Errorf("expected dashes %v with a phase, got %v", expected, intervals)
+	}
+	if intervals := dashIntervals(nil, 3, 10); !reflect.DeepEqual(intervals, [][2]float64{{0, 10}}) {
+		t.Errorf("expected a solid line, got %v", intervals)
+	}
+}
+
+func TestDashesRunOnAroundBends(t *testing.T) {
+	link := Link{}.Init(new(Resource).Init(), WINDROSE_E, ArrowHead{}, new(Resource).Init(), WINDROSE_W, ArrowHead{}, 1, color.RGBA{0, 0, 0, 255})
+	link.SetDashPattern([]float64{8, 4})
+	link.path = &linkPath{sourcePt: image.Pt(10, 10), controlPts: []image.Point{{20, 10}}, targetPt: image.Pt(20, 50)}
+	img := image.NewRGBA(image.Rect(0, 0, 60, 60))
+	if err := link.drawPolyline(img, link.path); err != nil {
+		t.Fatalf("drawPolyline failed: %v", err)
+	}
+	// The first segment is 10px long, so the second one starts in the middle of the first gap
+	if img.RGBAAt(20, 11).A != 0 {
+		t.Error("expected the gap to run on after the bend")
+	}
+	if img.RGBAAt(20, 13).A == 0 {
+		t.Error("expected the second dash after the gap")
+	}
+}
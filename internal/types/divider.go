@@ -0,0 +1,147 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package types
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/math/fixed"
+)
+
+const (
+	SPACER_SIZE       = 40 // default width and height of a spacer
+	DIVIDER_GAP       = 10 // between a divider and its siblings
+	DIVIDER_LABEL_GAP = 8  // between the rule and the label of a divider
+)
+
+// Spacer is an empty box that takes part in the layout of its parent. Its size is set with Width and Height.
+type Spacer struct {
+}
+
+func (s Spacer) Init() *Resource {
+	sr := new(Resource).Init()
+	sr.bindings = &image.Rectangle{
+		image.Point{0, 0},
+		image.Point{SPACER_SIZE, SPACER_SIZE},
+	}
+	sr.borderColor = &color.RGBA{0, 0, 0, 0}
+	sr.margin = &Margin{0, 0, 0, 0}
+	sr.padding = &Padding{0, 0, 0, 0}
+	return sr
+}
+
+// Divider is a rule with an optional label spanning the content of its parent. The rule is
+// horizontal in a vertical stack and vertical in a horizontal stack unless Direction is set.
+type Divider struct {
+}
+
+func (d Divider) Init() *Resource {
+	sr := new(Resource).Init()
+	sr.borderColor = &color.RGBA{125, 137, 152, 255}
+	sr.labelColor = &color.RGBA{125, 137, 152, 255}
+	sr.direction = ""
+	sr.divider = true
+	return sr
+}
+
+// dividerDirection returns the direction of the rule of a divider in the parent
+func (r *Resource) dividerDirection(parent *Resource) string {
+	if r.direction == "horizontal" || r.direction == "vertical" {
+		return r.direction
+	}
+	if parent != nil && parent.direction == "horizontal" {
+		return "vertical"
+	}
+	return "horizontal"
+}
+
+// scaleDivider sets the size of a divider before its parent places it. The length along the rule
+// is set afterwards by spanDividers.
+func (r *Resource) scaleDivider(parent *Resource, textWidth, textHeight int) {
+	if r.dividerDirection(parent) == "horizontal" {
+		r.bindings = &image.Rectangle{image.Point{0, 0}, image.Point{0, maxInt(textHeight, r.borderWidth)}}
+		if r.margin == nil {
+			r.margin = &Margin{DIVIDER_GAP, 0, DIVIDER_GAP, 0}
+		}
+	} else {
+		r.bindings = &image.Rectangle{image.Point{0, 0}, image.Point{maxInt(textWidth, r.borderWidth), 0}}
+		if r.margin == nil {
+			r.margin = &Margin{0, DIVIDER_GAP, 0, DIVIDER_GAP}
+		}
+	}
+	if r.padding == nil {
+		r.padding = &Padding{0, 0, 0, 0}
+	}
+}
+
+// spanDividers stretches the dividers among the children over the content area of b. The dividers
+// across the lines of a flow are spanned over their own line by layoutFlow.
+func (r *Resource) spanDividers(b image.Rectangle, textHeight int) {
+	for _, subResource := range r.layoutChildren() {
+		if !subResource.divider || (r.isFlow() && subResource.dividerDirection(r) != r.direction) {
+			continue
+		}
+		bindings := subResource.GetBindings()
+		if subResource.dividerDirection(r) == "horizontal" {
+			subResource.SetBindings(image.Rect(b.Min.X+r.padding.Left, bindings.Min.Y, b.Max.X-r.padding.Right, bindings.Max.Y))
+		} else {
+			subResource.SetBindings(image.Rect(bindings.Min.X, b.Min.Y+r.headerHeight(textHeight)+r.padding.Top, bindings.Max.X, b.Max.Y-r.padding.Bottom))
+		}
+	}
+}
+
+// drawDivider draws the rule through the middle of the bindings, interrupted by the label
+func (r *Resource) drawDivider(img *image.RGBA, parent *Resource) error {
+	r.drawn = true
+	horizontal := r.dividerDirection(parent) == "horizontal"
+	center := image.Point{(r.bindings.Min.X + r.bindings.Max.X) / 2, (r.bindings.Min.Y + r.bindings.Max.Y) / 2}
+	gap := image.Rectangle{center, center}
+	if r.label != "" {
+		face, err := r.prepareFontFace(false, parent)
+		if err != nil {
+			return fmt.Errorf("failed to prepare font face for drawing divider: %w", err)
+		}
+		textBindings, _ := font.BoundString(face, r.label)
+		w := textBindings.Max.X - textBindings.Min.X
+		h := textBindings.Max.Y - textBindings.Min.Y
+		d := &font.Drawer{
+			Dst:  img,
+			Src:  image.NewUniform(r.labelColor),
+			Face: face,
+			Dot: fixed.Point26_6{
+				X: fixed.I(center.X) - w/2 - textBindings.Min.X,
+				Y: fixed.I(center.Y) - h/2 - textBindings.Min.Y,
+			},
+		}
+		d.DrawString(r.label)
+		gap = image.Rect(
+			center.X-w.Ceil()/2-DIVIDER_LABEL_GAP,
+			center.Y-h.Ceil()/2-DIVIDER_LABEL_GAP,
+			center.X+w.Ceil()/2+DIVIDER_LABEL_GAP,
+			center.Y+h.Ceil()/2+DIVIDER_LABEL_GAP,
+		)
+	}
+
+	var rule image.Rectangle
+	if horizontal {
+		rule = image.Rect(r.bindings.Min.X, center.Y-r.borderWidth/2, r.bindings.Max.X, center.Y-r.borderWidth/2+r.borderWidth)
+	} else {
+		rule = image.Rect(center.X-r.borderWidth/2, r.bindings.Min.Y, center.X-r.borderWidth/2+r.borderWidth, r.bindings.Max.Y)
+	}
+	// The rule is drawn on both sides of the gap
+	var before, after image.Rectangle
+	if horizontal {
+		before = rule.Intersect(image.Rect(rule.Min.X, rule.Min.Y, gap.Min.X, rule.Max.Y))
+		after = rule.Intersect(image.Rect(gap.Max.X, rule.Min.Y, rule.Max.X, rule.Max.Y))
+	} else {
+		before = rule.Intersect(image.Rect(rule.Min.X, rule.Min.Y, rule.Max.X, gap.Min.Y))
+		after = rule.Intersect(image.Rect(rule.Min.X, gap.Max.Y, rule.Max.X, rule.Max.Y))
+	}
+	fillRect(img, before, *r.borderColor)
+	fillRect(img, after, *r.borderColor)
+	return nil
+}
@@ -0,0 +1,116 @@
+package types
+
+import (
+	"image"
+	"testing"
+)
+
+func TestSpacer(t *testing.T) {
+	stack := HorizontalStack{}.Init()
+	spacer := Spacer{}.Init()
+	spacer.SetSize(100, 0)
+	cells := []*Resource{newGridCell(50, 50), spacer, newGridCell(50, 50)}
+	for _, c := range cells {
+		if err := stack.AddChild(c); err != nil {
+			t.Fatalf("AddChild failed: %v", err)
+		}
+	}
+	if err := stack.Scale(nil, nil); err != nil {
+		t.Fatalf("Scale failed: %v", err)
+	}
+	if spacer.GetBindings().Size() != image.Pt(100, SPACER_SIZE) {
+		t.Errorf("expected spacer size %v, got %v", image.Pt(100, SPACER_SIZE), spacer.GetBindings().Size())
+	}
+	if cells[2].GetBindings().Min.X != 150 {
+		t.Errorf("expected the last cell at x=150, got %v", cells[2].GetBindings())
+	}
+}
+
+func TestDivider(t *testing.T) {
+	stack := VerticalStack{}.Init()
+	stack.SetPadding(Padding{0, 10, 0, 10})
+	divider := Divider{}.Init()
+	cells := []*Resource{newGridCell(200, 50), divider, newGridCell(50, 50)}
+	for _, c := range cells {
+		if err := stack.AddChild(c); err != nil {
+			t.Fatalf("AddChild failed: %v", err)
+		}
+	}
+	if err := stack.Scale(nil, nil); err != nil {
+		t.Fatalf("Scale failed: %v", err)
+	}
+
+	// The rule spans the content of the stack between the cells
+	b := stack.GetBindings()
+	d := divider.GetBindings()
+	if d.Min.X != b.Min.X+10 || d.Max.X != b.Max.X-10 {
+		t.Errorf("expected divider from x=%d to x=%d, got %v", b.Min.X+10, b.Max.X-10, d)
+	}
+	if d.Min.Y != cells[0].GetBindings().Max.Y+DIVIDER_GAP || cells[2].GetBindings().Min.Y != d.Max.Y+DIVIDER_GAP {
+		t.Errorf("expected divider between the cells with a gap of %d, got %v %v %v", DIVIDER_GAP, cells[0].GetBindings(), d, cells[2].GetBindings())
+	}
+	if divider.dividerDirection(stack) != "horizontal" {
+		t.Errorf("expected a horizontal rule in a vertical stack, got %s", divider.dividerDirection(stack))
+	}
+	if divider.dividerDirection(HorizontalStack{}.Init()) != "vertical" {
+		t.Error("expected a vertical rule in a horizontal stack")
+	}
+}
+
+func TestDividerInFlow(t *testing.T) {
+	stack := HorizontalStack{}.Init()
+	stack.SetWrap(2, 0)
+	divider := Divider{}.Init()
+	cells := []*Resource{newGridCell(50, 50), divider, newGridCell(50, 80), newGridCell(50, 30)}
+	for _, c := range cells {
+		if err := stack.AddChild(c); err != nil {
+			t.Fatalf("AddChild failed: %v", err)
+		}
+	}
+	if err := stack.Scale(nil, nil); err != nil {
+		t.Fatalf("Scale failed: %v", err)
+	}
+
+	// The rule spans the first line only, as high as the first cell
+	d := divider.GetBindings()
+	first := cells[0].GetBindings()
+	if d.Min.Y != first.Min.Y || d.Max.Y != first.Max.Y {
+		t.Errorf("expected divider from y=%d to y=%d, got %v", first.Min.Y, first.Max.Y, d)
+	}
+	if d.Max.Y > cells[2].GetBindings().Min.Y {
+		t.Errorf("expected the divider above the second line, got %v and %v", d, cells[2].GetBindings())
+	}
+}
+
+func TestDividerBorderWidth(t *testing.T) {
+	stack := VerticalStack{}.Init()
+	divider := Divider{}.Init()
+	divider.SetBorderWidth(6)
+	for _, c := range []*Resource{newGridCell(100, 50), divider} {
+		if err := stack.AddChild(c); err != nil {
+			t.Fatalf("AddChild failed: %v", err)
+		}
+	}
+	if err := stack.Scale(nil, nil); err != nil {
+		t.Fatalf("Scale failed: %v", err)
+	}
+	if h := divider.GetBindings().Dy(); h != 6 {
+		t.Errorf("expected the divider 6px high, got %d", h)
+	}
+
+	b := stack.GetBindings()
+	img := image.NewRGBA(image.Rect(0, 0, b.Max.X+10, b.Max.Y+10))
+	if err := divider.drawDivider(img, stack); err != nil {
+		t.Fatalf("drawDivider failed: %v", err)
+	}
+	d := divider.GetBindings()
+	drawn := 0
+	for y := d.Min.Y; y < d.Max.Y; y++ {
+		if img.RGBAAt((d.Min.X+d.Max.X)/2, y).A != 0 {
+			drawn++
+		}
+	}
+	if drawn != 6 {
+		t.Errorf("expected a rule 6px thick, got %d", drawn)
+	}
+}
@@ -0,0 +1,126 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package types
+
+import (
+	"fmt"
+	"image"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// isFlow reports whether the children of a stack wrap onto several rows or columns
+func (r *Resource) isFlow() bool {
+	if r.direction != "horizontal" && r.direction != "vertical" {
+		return false
+	}
+	return r.wrap > 0 || r.maxWidth > 0
+}
+
+// layoutFlow places the already scaled children on rows (horizontal) or columns (vertical),
+// starting a new one after Wrap children or before a row gets wider than MaxWidth.
+// Rows are stacked from top to bottom and columns from left to right, also in reverse directions.
+// With Align stretch, the children are stretched to the thickest child of their own line, and the
+// dividers across the lines span their own line.
+func (r *Resource) layoutFlow() error {
+	horizontal := r.direction == "horizontal"
+	if !horizontal && r.maxWidth > 0 {
+		return fmt.Errorf("MaxWidth is only for horizontal stacks, use Wrap on the vertical stack %s", r.label)
+	}
+	outerSize := func(child *Resource) (int, int) {
+		b := child.GetBindings()
+		m := child.GetMargin()
+		width := b.Dx() + m.Left + m.Right
+		height := b.Dy() + m.Top + m.Bottom
+		if horizontal {
+			return width, height
+		}
+		return height, width
+	}
+
+	children := r.layoutChildren()
+	lines := [][]*Resource{}
+	line := []*Resource{}
+	lineLength := 0
+	for _, child := range children {
+		length, _ := outerSize(child)
+		if len(line) > 0 && ((r.wrap > 0 && len(line) >= r.wrap) || (horizontal && r.maxWidth > 0 && lineLength+length > r.maxWidth)) {
+			lines = append(lines, line)
+			line = []*Resource{}
+			lineLength = 0
+		}
+		line = append(line, child)
+		lineLength += length
+	}
+	lines = append(lines, line)
+	if r.align == "stretch" && !r.equalSize {
+		for _, line := range lines {
+			if err := r.stretchResources(line); err != nil {
+				return err
+			}
+		}
+	}
+	longest := 0
+	for _, line := range lines {
+		lineLength := 0
+		for _, child := range line {
+			length, _ := outerSize(child)
+			lineLength += length
+		}
+		longest = maxInt(longest, lineLength)
+	}
+	log.Infof("Flow %s: %d children on %d lines", r.label, len(children), len(lines))
+
+	crossPos := 0
+	for _, line := range lines {
+		lineThickness := 0
+		for _, child := range line {
+			_, thickness := outerSize(child)
+			lineThickness = maxInt(lineThickness, thickness)
+		}
+		pos := 0
+		for _, child := range line {
+			length, thickness := outerSize(child)
+			var offset int
+			switch {
+			case r.align == "center" || r.align == "stretch":
+				offset = (lineThickness - thickness) / 2
+			case horizontal && r.align == "top", !horizontal && r.align == "left":
+				offset = 0
+			case horizontal && r.align == "bottom", !horizontal && r.align == "right":
+				offset = lineThickness - thickness
+			default:
+				return fmt.Errorf("unknown align %s in the direction(%s) on %s", r.align, r.direction, r.label)
+			}
+			start := pos
+			if r.reverse {
+				// Lines are filled from right to left or from bottom to top
+				start = longest - pos - length
+			}
+			bindings := child.GetBindings()
+			margin := child.GetMargin()
+			var err error
+			if horizontal {
+				err = child.Translation(start+margin.Left-bindings.Min.X, crossPos+offset+margin.Top-bindings.Min.Y)
+			} else {
+				err = child.Translation(crossPos+offset+margin.Left-bindings.Min.X, start+margin.Top-bindings.Min.Y)
+			}
+			if err != nil {
+				return fmt.Errorf("failed to translate subresource: %w", err)
+			}
+			if child.divider && child.dividerDirection(r) != r.direction {
+				// A divider across the line spans its own line only
+				b := child.GetBindings()
+				if horizontal {
+					child.SetBindings(image.Rect(b.Min.X, crossPos+margin.Top, b.Max.X, crossPos+lineThickness-margin.Bottom))
+				} else {
+					child.SetBindings(image.Rect(crossPos+margin.Left, b.Min.Y, crossPos+lineThickness-margin.Right, b.Max.Y))
+				}
+			}
+			pos += length
+		}
+		crossPos += lineThickness
+	}
+	return nil
+}
@@ -0,0 +1,119 @@
+package types
+
+import (
+	"image"
+	"testing"
+)
+
+func TestFlowLayout(t *testing.T) {
+	testCases := []struct {
+		name      string
+		direction string
+		align     string
+		wrap      int
+		maxWidth  int
+		expected  []image.Point
+	}{
+		{"Wrap horizontal", "horizontal", "top", 2, 0, []image.Point{{0, 0}, {50, 0}, {0, 50}}},
+		{"MaxWidth horizontal", "horizontal", "top", 0, 120, []image.Point{{0, 0}, {50, 0}, {0, 50}}},
+		{"Wrap vertical", "vertical", "left", 2, 0, []image.Point{{0, 0}, {0, 30}, {50, 0}}},
+		{"Wrap horizontal bottom", "horizontal", "bottom", 2, 0, []image.Point{{0, 20}, {50, 0}, {0, 50}}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			stack := HorizontalStack{}.Init()
+			stack.SetDirection(tc.direction)
+			stack.SetAlign(tc.align)
+			stack.SetWrap(tc.wrap, tc.maxWidth)
+			cells := []*Resource{newGridCell(50, 30), newGridCell(50, 50), newGridCell(50, 50)}
+			for _, c := range cells {
+				if err := stack.AddChild(c); err != nil {
+					t.Fatalf("AddChild failed: %v", err)
+				}
+			}
+			if err := stack.Scale(nil, nil); err != nil {
+				t.Fatalf("Scale failed: %v", err)
+			}
+			for i, c := range cells {
+				if c.GetBindings().Min != tc.expected[i] {
+					t.Errorf("cell %d: expected position %v, got %v", i, tc.expected[i], c.GetBindings().Min)
+				}
+			}
+		})
+	}
+}
+
+func TestFlowLayoutUnknownAlign(t *testing.T) {
+	stack := HorizontalStack{}.Init()
+	stack.SetAlign("left")
+	stack.SetWrap(2, 0)
+	if err := stack.AddChild(newGridCell(10, 10)); err != nil {
+		t.Fatalf("AddChild failed: %v", err)
+	}
+	if err := stack.Scale(nil, nil); err == nil {
+		t.Error("expected error for align left in a horizontal flow")
+	}
+}
+
+func TestFlowLayoutReverse(t *testing.T) {
+	stack := HorizontalStack{}.Init()
+	stack.SetDirection("horizontal-reverse")
+	stack.SetAlign("top")
+	stack.SetWrap(2, 0)
+	cells := []*Resource{newGridCell(50, 50), newGridCell(50, 50), newGridCell(50, 50)}
+	for _, c := range cells {
+		if err := stack.AddChild(c); err != nil {
+			t.Fatalf("AddChild failed: %v", err)
+		}
+	}
+	if err := stack.Scale(nil, nil); err != nil {
+		t.Fatalf("Scale failed: %v", err)
+	}
+	expected := []image.Point{{50, 0}, {0, 0}, {50, 50}}
+	for i, c := range cells {
+		if c.GetBindings().Min != expected[i] {
+			t.Errorf("cell %d: expected position %v, got %v", i, expected[i], c.GetBindings().Min)
+		}
+	}
+}
+
+func TestFlowLayoutStretchPerLine(t *testing.T) {
+	// The first row holds a short and a tall group, the second row two short groups
+	short1 := newStretchGroup(t, "horizontal", newGridCell(50, 50))
+	tall := newStretchGroup(t, "vertical", newGridCell(50, 50), newGridCell(50, 50))
+	short2 := newStretchGroup(t, "horizontal", newGridCell(50, 50))
+	short3 := newStretchGroup(t, "horizontal", newGridCell(50, 50))
+	stack := HorizontalStack{}.Init()
+	stack.SetAlign("stretch")
+	stack.SetWrap(2, 0)
+	for _, c := range []*Resource{short1, tall, short2, short3} {
+		if err := stack.AddChild(c); err != nil {
+			t.Fatalf("AddChild failed: %v", err)
+		}
+	}
+	if err := stack.Scale(nil, nil); err != nil {
+		t.Fatalf("Scale failed: %v", err)
+	}
+
+	if short1.GetBindings().Dy() != tall.GetBindings().Dy() {
+		t.Errorf("expected the first row to share the height of the tall group, got %v and %v", short1.GetBindings(), tall.GetBindings())
+	}
+	if short2.GetBindings().Dy() >= tall.GetBindings().Dy() {
+		t.Errorf("expected the second row to keep its own height, got %v", short2.GetBindings())
+	}
+	if short2.GetBindings().Dy() != short3.GetBindings().Dy() {
+		t.Errorf("expected the same height within the second row, got %v and %v", short2.GetBindings(), short3.GetBindings())
+	}
+}
+
+func TestFlowLayoutMaxWidthVertical(t *testing.T) {
+	stack := VerticalStack{}.Init()
+	stack.SetWrap(0, 100)
+	if err := stack.AddChild(newGridCell(10, 10)); err != nil {
+		t.Fatalf("AddChild failed: %v", err)
+	}
+	if err := stack.Scale(nil, nil); err == nil {
+		t.Error("expected error for MaxWidth on a vertical stack")
+	}
+}
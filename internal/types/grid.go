@@ -0,0 +1,209 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package types
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"math"
+	"sort"
+
+	log "github.com/sirupsen/logrus"
+)
+
+type Grid struct {
+}
+
+func (g Grid) Init() *Resource {
+	sr := Resource{}
+	sr.bindings = &image.Rectangle{
+		image.Point{0, 0},
+		image.Point{320, 190},
+	}
+	sr.iconImage = image.NewRGBA(*sr.bindings)
+	sr.iconBounds = image.Rect(0, 0, 0, 0)
+	sr.borderColor = &color.RGBA{0, 0, 0, 0}
+	sr.borderWidth = WIDTH
+	sr.fillColor = color.RGBA{0, 0, 0, 0}
+	sr.label = ""
+	sr.labelColor = &color.RGBA{0, 0, 0, 0}
+	sr.margin = &Margin{0, 0, 0, 0}
+	sr.padding = &Padding{0, 0, 0, 0}
+	sr.direction = "grid"
+	sr.align = "center"
+	sr.columnSpan = 1
+	sr.rowSpan = 1
+	sr.rowAlign = "center"
+	sr.columnAlign = "center"
+	return &sr
+}
+
+type gridCell struct {
+	resource   *Resource
+	row        int
+	column     int
+	rowSpan    int
+	columnSpan int
+}
+
+// placeGridCells assigns every child to a cell in row-major order, skipping cells
+// that are already covered by a spanning child placed earlier.
+func placeGridCells(children []*Resource, columns int) ([]gridCell, int) {
+	cells := []gridCell{}
+	occupied := map[image.Point]bool{}
+	isFree := func(row, column, rowSpan, columnSpan int) bool {
+		for y := row; y < row+rowSpan; y++ {
+			for x := column; x < column+columnSpan; x++ {
+				if occupied[image.Point{x, y}] {
+					return false
+				}
+			}
+		}
+		return true
+	}
+
+	rows := 0
+	row, column := 0, 0
+	for _, child := range children {
+		columnSpan := minInt(maxInt(child.columnSpan, 1), columns)
+		rowSpan := maxInt(child.rowSpan, 1)
+		for {
+			if column+columnSpan > columns {
+				column = 0
+				row++
+				continue
+			}
+			if isFree(row, column, rowSpan, columnSpan) {
+				break
+			}
+			column++
+		}
+		for y := row; y < row+rowSpan; y++ {
+			for x := column; x < column+columnSpan; x++ {
+				occupied[image.Point{x, y}] = true
+			}
+		}
+		cells = append(cells, gridCell{
+			resource:   child,
+			row:        row,
+			column:     column,
+			rowSpan:    rowSpan,
+			columnSpan: columnSpan,
+		})
+		rows = maxInt(rows, row+rowSpan)
+		column += columnSpan
+	}
+	return cells, rows
+}
+
+// gridTrackSizes returns the size of each track (column or row) so that every cell fits.
+// Single-span cells are sized first, then spanning cells share out what they still need.
+func gridTrackSizes(count int, cells []gridCell, start func(gridCell) int, span func(gridCell) int, size func(gridCell) int) []int {
+	sizes := make([]int, count)
+	spanning := []gridCell{}
+	for _, cell := range cells {
+		if span(cell) == 1 {
+			sizes[start(cell)] = maxInt(sizes[start(cell)], size(cell))
+		} else {
+			spanning = append(spanning, cell)
+		}
+	}
+	sort.SliceStable(spanning, func(i, j int) bool {
+		return span(spanning[i]) < span(spanning[j])
+	})
+	for _, cell := range spanning {
+		current := 0
+		for i := start(cell); i < start(cell)+span(cell); i++ {
+			current += sizes[i]
+		}
+		lack := size(cell) - current
+		if lack <= 0 {
+			continue
+		}
+		for i := start(cell); i < start(cell)+span(cell); i++ {
+			sizes[i] += lack / span(cell)
+		}
+		sizes[start(cell)+span(cell)-1] += lack % span(cell)
+	}
+	return sizes
+}
+
+// layoutGrid places the already scaled children on a grid so that cells line up across rows and columns.
+func (r *Resource) layoutGrid() error {
+	children := r.layoutChildren()
+	if len(children) == 0 {
+		return nil
+	}
+	columns := r.gridColumns
+	if columns <= 0 {
+		if r.gridRows > 0 {
+			columns = (len(children) + r.gridRows - 1) / r.gridRows
+		} else {
+			columns = int(math.Ceil(math.Sqrt(float64(len(children)))))
+		}
+	}
+	cells, rows := placeGridCells(children, columns)
+	rows = maxInt(rows, r.gridRows)
+	log.Infof("Grid %s: %d columns x %d rows", r.label, columns, rows)
+
+	columnWidths := gridTrackSizes(columns, cells,
+		func(c gridCell) int { return c.column },
+		func(c gridCell) int { return c.columnSpan },
+		func(c gridCell) int {
+			m := c.resource.GetMargin()
+			return c.resource.GetBindings().Dx() + m.Left + m.Right
+		},
+	)
+	rowHeights := gridTrackSizes(rows, cells,
+		func(c gridCell) int { return c.row },
+		func(c gridCell) int { return c.rowSpan },
+		func(c gridCell) int {
+			m := c.resource.GetMargin()
+			return c.resource.GetBindings().Dy() + m.Top + m.Bottom
+		},
+	)
+
+	columnX := make([]int, columns+1)
+	for i, w := range columnWidths {
+		columnX[i+1] = columnX[i] + w
+	}
+	rowY := make([]int, rows+1)
+	for i, h := range rowHeights {
+		rowY[i+1] = rowY[i] + h
+	}
+
+	for _, cell := range cells {
+		bindings := cell.resource.GetBindings()
+		margin := cell.resource.GetMargin()
+		x1, x2 := columnX[cell.column], columnX[cell.column+cell.columnSpan]
+		y1, y2 := rowY[cell.row], rowY[cell.row+cell.rowSpan]
+
+		var x, y int
+		switch r.columnAlign {
+		case "left":
+			x = x1 + margin.Left
+		case "center", "":
+			x = x1 + (x2-x1-bindings.Dx()-margin.Left-margin.Right)/2 + margin.Left
+		case "right":
+			x = x2 - margin.Right - bindings.Dx()
+		default:
+			return fmt.Errorf("unknown column align %s in the grid on %s", r.columnAlign, r.label)
+		}
+		switch r.rowAlign {
+		case "top":
+			y = y1 + margin.Top
+		case "center", "":
+			y = y1 + (y2-y1-bindings.Dy()-margin.Top-margin.Bottom)/2 + margin.Top
+		case "bottom":
+			y = y2 - margin.Bottom - bindings.Dy()
+		default:
+			return fmt.Errorf("unknown row align %s in the grid on %s", r.rowAlign, r.label)
+		}
+		if err := cell.resource.Translation(x-bindings.Min.X, y-bindings.Min.Y); err != nil {
+			return fmt.Errorf("failed to translate grid cell: %w", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,127 @@
+package types
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+func TestGridInit(t *testing.T) {
+	resource := Grid{}.Init()
+
+	if *resource.bindings != image.Rect(0, 0, 320, 190) {
+		t.Errorf("Incorrect bindings: %v", resource.bindings)
+	}
+
+	if *resource.borderColor != (color.RGBA{0, 0, 0, 0}) {
+		t.Errorf("Incorrect borderColor: %v", resource.borderColor)
+	}
+
+	if *resource.margin != (Margin{0, 0, 0, 0}) {
+		t.Errorf("Incorrect margin: %v", resource.margin)
+	}
+
+	if *resource.padding != (Padding{0, 0, 0, 0}) {
+		t.Errorf("Incorrect padding: %v", resource.padding)
+	}
+
+	if resource.direction != "grid" {
+		t.Errorf("Incorrect direction: %s", resource.direction)
+	}
+
+	if resource.rowAlign != "center" || resource.columnAlign != "center" {
+		t.Errorf("Incorrect align: row=%s column=%s", resource.rowAlign, resource.columnAlign)
+	}
+}
+
+func newGridCell(width, height int) *Resource {
+	r := new(Resource).Init()
+	r.SetBindings(image.Rect(0, 0, width, height))
+	r.SetMargin(Margin{0, 0, 0, 0})
+	r.SetPadding(Padding{0, 0, 0, 0})
+	return r
+}
+
+func TestGridLayout(t *testing.T) {
+	grid := Grid{}.Init()
+	grid.SetGrid(2, 0)
+	cells := []*Resource{
+		newGridCell(100, 50),
+		newGridCell(40, 80),
+		newGridCell(60, 30),
+		newGridCell(20, 20),
+	}
+	for _, c := range cells {
+		if err := grid.AddChild(c); err != nil {
+			t.Fatalf("AddChild failed: %v", err)
+		}
+	}
+	if err := grid.Scale(nil, nil); err != nil {
+		t.Fatalf("Scale failed: %v", err)
+	}
+
+	// Columns are 100 and 40 wide, rows are 80 and 30 high.
+	expected := []image.Rectangle{
+		image.Rect(0, 15, 100, 65),
+		image.Rect(100, 0, 140, 80),
+		image.Rect(20, 80, 80, 110),
+		image.Rect(110, 85, 130, 105),
+	}
+	for i, c := range cells {
+		if c.GetBindings() != expected[i] {
+			t.Errorf("cell %d: expected bindings %v, got %v", i, expected[i], c.GetBindings())
+		}
+	}
+	if grid.GetBindings() != image.Rect(0, 0, 140, 110) {
+		t.Errorf("expected grid bindings %v, got %v", image.Rect(0, 0, 140, 110), grid.GetBindings())
+	}
+}
+
+func TestGridLayoutSpan(t *testing.T) {
+	grid := Grid{}.Init()
+	grid.SetGrid(2, 0)
+	grid.SetGridAlign("top", "left")
+	wide := newGridCell(200, 10)
+	wide.SetGridSpan(2, 1)
+	tall := newGridCell(10, 100)
+	tall.SetGridSpan(1, 2)
+	small1 := newGridCell(30, 30)
+	small2 := newGridCell(30, 30)
+	for _, c := range []*Resource{wide, tall, small1, small2} {
+		if err := grid.AddChild(c); err != nil {
+			t.Fatalf("AddChild failed: %v", err)
+		}
+	}
+	if err := grid.Scale(nil, nil); err != nil {
+		t.Fatalf("Scale failed: %v", err)
+	}
+
+	// wide: row 0 spanning both columns, tall: column 0 spanning rows 1-2,
+	// small1 and small2 fill column 1 on rows 1 and 2.
+	testCases := []struct {
+		name     string
+		resource *Resource
+		expected image.Point
+	}{
+		{"wide", wide, image.Point{0, 0}},
+		{"tall", tall, image.Point{0, 10}},
+		{"small1", small1, image.Point{90, 10}},
+		{"small2", small2, image.Point{90, 60}},
+	}
+	for _, tc := range testCases {
+		if tc.resource.GetBindings().Min != tc.expected {
+			t.Errorf("%s: expected position %v, got %v", tc.name, tc.expected, tc.resource.GetBindings().Min)
+		}
+	}
+}
+
+func TestGridLayoutUnknownAlign(t *testing.T) {
+	grid := Grid{}.Init()
+	grid.SetGridAlign("middle", "")
+	if err := grid.AddChild(newGridCell(10, 10)); err != nil {
+		t.Fatalf("AddChild failed: %v", err)
+	}
+	if err := grid.Scale(nil, nil); err == nil {
+		t.Error("expected error for unknown row align")
+	}
+}
@@ -0,0 +1,342 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package types
+
+import (
+	"fmt"
+	"image"
+	"math"
+	"sort"
+
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	LAYERED_LAYER_GAP  = 40 // between two layers, in addition to the margins of the resources
+	LAYERED_DUMMY_SIZE = 20 // room kept in a layer for a link passing through it
+	LAYERED_SWEEPS     = 8  // iterations of the crossing reduction
+)
+
+type layeredNode struct {
+	resource *Resource // nil for dummy nodes of links spanning several layers
+	layer    int
+	size     image.Point
+	up       []int
+	down     []int
+	y        float64
+}
+
+// layoutLayered places the already scaled children with a layered (Sugiyama) layout over the
+// links between them. Layers go from left to right along the direction of the links.
+func (r *Resource) layoutLayered() error {
+	children := r.layoutChildren()
+	n := len(children)
+	if n == 0 {
+		return nil
+	}
+
+	// Links between descendants belong to the children that contain them
+	descendants := make([][]*Resource, n)
+	owner := map[*Resource]int{}
+	for i, child := range children {
+		descendants[i] = collectDescendants(child, nil)
+		for _, d := range descendants[i] {
+			owner[d] = i
+		}
+	}
+	type edge struct{ from, to int }
+	seen := map[edge]bool{}
+	edges := []edge{}
+	for i := range children {
+		for _, d := range descendants[i] {
+			for _, link := range d.links {
+				if link.Source != d {
+					continue
+				}
+				j, ok := owner[link.Target]
+				e := edge{i, j}
+				if !ok || i == j || seen[e] {
+					continue
+				}
+				seen[e] = true
+				edges = append(edges, e)
+			}
+		}
+	}
+
+	// 1. Break cycles by reversing the edges that go back to a node on the DFS stack
+	outgoing := make([][]int, n)
+	for _, e := range edges {
+		outgoing[e.from] = append(outgoing[e.from], e.to)
+	}
+	state := make([]int, n) // 0: unvisited, 1: on stack, 2: done
+	dag := []edge{}
+	inDag := map[edge]bool{}
+	addEdge := func(e edge) {
+		if !inDag[e] {
+			inDag[e] = true
+			dag = append(dag, e)
+		}
+	}
+	var visit func(v int)
+	visit = func(v int) {
+		state[v] = 1
+		for _, w := range outgoing[v] {
+			switch state[w] {
+			case 0:
+				addEdge(edge{v, w})
+				visit(w)
+			case 1:
+				addEdge(edge{w, v})
+			case 2:
+				addEdge(edge{v, w})
+			}
+		}
+		state[v] = 2
+	}
+	for v := 0; v < n; v++ {
+		if state[v] == 0 {
+			visit(v)
+		}
+	}
+
+	// 2. Assign layers by the longest path from the sources
+	layer := make([]int, n)
+	inDegree := make([]int, n)
+	successors := make([][]int, n)
+	predecessors := make([][]int, n)
+	for _, e := range dag {
+		successors[e.from] = append(successors[e.from], e.to)
+		predecessors[e.to] = append(predecessors[e.to], e.from)
+		inDegree[e.to]++
+	}
+	queue := []int{}
+	for v := 0; v < n; v++ {
+		if inDegree[v] == 0 {
+			queue = append(queue, v)
+		}
+	}
+	topological := []int{}
+	for len(queue) > 0 {
+		v := queue[0]
+		queue = queue[1:]
+		topological = append(topological, v)
+		for _, w := range successors[v] {
+			layer[w] = maxInt(layer[w], layer[v]+1)
+			inDegree[w]--
+			if inDegree[w] == 0 {
+				queue = append(queue, w)
+			}
+		}
+	}
+	// Sources are moved next to their nearest successor
+	for i := len(topological) - 1; i >= 0; i-- {
+		v := topological[i]
+		if len(predecessors[v]) != 0 || len(successors[v]) == 0 {
+			continue
+		}
+		nearest := math.MaxInt
+		for _, w := range successors[v] {
+			nearest = minInt(nearest, layer[w])
+		}
+		layer[v] = nearest - 1
+	}
+
+	nodes := make([]*layeredNode, n)
+	layerCount := 0
+	for i, child := range children {
+		b := child.GetBindings()
+		m := child.GetMargin()
+		nodes[i] = &layeredNode{
+			resource: child,
+			layer:    layer[i],
+			size:     image.Point{b.Dx() + m.Left + m.Right, b.Dy() + m.Top + m.Bottom},
+		}
+		layerCount = maxInt(layerCount, layer[i]+1)
+	}
+
+	// 3. Split the links spanning several layers with dummy nodes
+	connect := func(from, to int) {
+		nodes[from].down = append(nodes[from].down, to)
+		nodes[to].up = append(nodes[to].up, from)
+	}
+	for v := 0; v < n; v++ {
+		for _, w := range successors[v] {
+			prev := v
+			for l := layer[v] + 1; l < layer[w]; l++ {
+				nodes = append(nodes, &layeredNode{
+					layer: l,
+					size:  image.Point{0, LAYERED_DUMMY_SIZE},
+				})
+				connect(prev, len(nodes)-1)
+				prev = len(nodes) - 1
+			}
+			connect(prev, w)
+		}
+	}
+	layers := make([][]int, layerCount)
+	for i, node := range nodes {
+		layers[node.layer] = append(layers[node.layer], i)
+	}
+
+	// 4. Reduce crossings with barycenter sweeps, keeping the best order found
+	best := copyLayers(layers)
+	bestCrossings := countAllCrossings(nodes, layers)
+	for sweep := 0; sweep < LAYERED_SWEEPS && bestCrossings > 0; sweep++ {
+		if sweep%2 == 0 {
+			for l := 1; l < layerCount; l++ {
+				orderByBarycenter(nodes, layers[l], layers[l-1], func(node *layeredNode) []int { return node.up })
+			}
+		} else {
+			for l := layerCount - 2; l >= 0; l-- {
+				orderByBarycenter(nodes, layers[l], layers[l+1], func(node *layeredNode) []int { return node.down })
+			}
+		}
+		if crossings := countAllCrossings(nodes, layers); crossings < bestCrossings {
+			best = copyLayers(layers)
+			bestCrossings = crossings
+		}
+	}
+	layers = best
+	log.Infof("Layered %s: %d layers, %d crossings", r.label, layerCount, bestCrossings)
+
+	// 5. Place each node next to its neighbors in the previous layer without overlapping
+	for l, ids := range layers {
+		desired := make([]float64, len(ids))
+		for i, id := range ids {
+			desired[i] = math.NaN()
+			if l == 0 || len(nodes[id].up) == 0 {
+				continue
+			}
+			sum := 0.0
+			for _, u := range nodes[id].up {
+				sum += nodes[u].y + float64(nodes[u].size.Y)/2
+			}
+			desired[i] = sum/float64(len(nodes[id].up)) - float64(nodes[id].size.Y)/2
+		}
+		forward := make([]float64, len(ids))
+		for i := range ids {
+			limit := math.Inf(-1)
+			if i > 0 {
+				limit = forward[i-1] + float64(nodes[ids[i-1]].size.Y)
+			} else if math.IsNaN(desired[i]) {
+				limit = 0
+			}
+			forward[i] = limit
+			if !math.IsNaN(desired[i]) {
+				forward[i] = math.Max(desired[i], limit)
+			}
+		}
+		backward := make([]float64, len(ids))
+		for i := len(ids) - 1; i >= 0; i-- {
+			limit := math.Inf(1)
+			if i < len(ids)-1 {
+				limit = backward[i+1] - float64(nodes[ids[i]].size.Y)
+			} else if math.IsNaN(desired[i]) {
+				limit = forward[i]
+			}
+			backward[i] = limit
+			if !math.IsNaN(desired[i]) {
+				backward[i] = math.Min(desired[i], limit)
+			}
+		}
+		for i, id := range ids {
+			nodes[id].y = (forward[i] + backward[i]) / 2
+		}
+	}
+
+	x := 0
+	for _, ids := range layers {
+		if len(ids) == 0 {
+			continue
+		}
+		width := 0
+		for _, id := range ids {
+			width = maxInt(width, nodes[id].size.X)
+		}
+		for _, id := range ids {
+			node := nodes[id]
+			if node.resource == nil {
+				continue
+			}
+			bindings := node.resource.GetBindings()
+			margin := node.resource.GetMargin()
+			dx := x + (width-node.size.X)/2 + margin.Left - bindings.Min.X
+			dy := int(math.Round(node.y)) + margin.Top - bindings.Min.Y
+			if err := node.resource.Translation(dx, dy); err != nil {
+				return fmt.Errorf("failed to translate layered node: %w", err)
+			}
+		}
+		x += width + LAYERED_LAYER_GAP
+	}
+	return nil
+}
+
+// collectDescendants returns the resource and everything drawn inside or on the border of it
+func collectDescendants(r *Resource, descendants []*Resource) []*Resource {
+	descendants = append(descendants, r)
+	for _, child := range r.children {
+		descendants = collectDescendants(child, descendants)
+	}
+	for _, borderChild := range r.borderChildren {
+		descendants = collectDescendants(borderChild.Resource, descendants)
+	}
+	return descendants
+}
+
+func orderByBarycenter(nodes []*layeredNode, ids, fixed []int, neighbors func(*layeredNode) []int) {
+	position := map[int]int{}
+	for i, id := range fixed {
+		position[id] = i
+	}
+	barycenter := map[int]float64{}
+	for i, id := range ids {
+		adjacent := neighbors(nodes[id])
+		if len(adjacent) == 0 {
+			// Nodes without neighbors keep their place
+			barycenter[id] = float64(i) * float64(len(fixed)) / float64(maxInt(len(ids), 1))
+			continue
+		}
+		sum := 0
+		for _, a := range adjacent {
+			sum += position[a]
+		}
+		barycenter[id] = float64(sum) / float64(len(adjacent))
+	}
+	sort.SliceStable(ids, func(i, j int) bool {
+		return barycenter[ids[i]] < barycenter[ids[j]]
+	})
+}
+
+func countAllCrossings(nodes []*layeredNode, layers [][]int) int {
+	crossings := 0
+	for l := 0; l+1 < len(layers); l++ {
+		position := map[int]int{}
+		for i, id := range layers[l+1] {
+			position[id] = i
+		}
+		segments := [][2]int{}
+		for i, id := range layers[l] {
+			for _, w := range nodes[id].down {
+				segments = append(segments, [2]int{i, position[w]})
+			}
+		}
+		for i := range segments {
+			for j := i + 1; j < len(segments); j++ {
+				if (segments[i][0]-segments[j][0])*(segments[i][1]-segments[j][1]) < 0 {
+					crossings++
+				}
+			}
+		}
+	}
+	return crossings
+}
+
+func copyLayers(layers [][]int) [][]int {
+	result := make([][]int, len(layers))
+	for i, ids := range layers {
+		result[i] = append([]int{}, ids...)
+	}
+	return result
+}
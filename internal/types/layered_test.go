@@ -0,0 +1,90 @@
+package types
+
+import (
+	"image/color"
+	"testing"
+)
+
+func newLayeredGroup(t *testing.T, count int, links [][2]int) (*Resource, []*Resource) {
+	group := new(Resource).Init()
+	group.SetDirection("auto-graph")
+	group.SetMargin(Margin{0, 0, 0, 0})
+	group.SetPadding(Padding{0, 0, 0, 0})
+	nodes := make([]*Resource, count)
+	for i := range nodes {
+		nodes[i] = newGridCell(64, 64)
+		if err := group.AddChild(nodes[i]); err != nil {
+			t.Fatalf("AddChild failed: %v", err)
+		}
+	}
+	for _, l := range links {
+		link := Link{}.Init(nodes[l[0]], WINDROSE_AUTO, ArrowHead{}, nodes[l[1]], WINDROSE_AUTO, ArrowHead{}, 2, color.RGBA{0, 0, 0, 255})
+		nodes[l[0]].AddLink(link)
+		nodes[l[1]].AddLink(link)
+	}
+	return group, nodes
+}
+
+func TestLayeredLayoutChain(t *testing.T) {
+	// Children are listed in the reverse order of the links: 2 -> 1 -> 0
+	group, nodes := newLayeredGroup(t, 3, [][2]int{{2, 1}, {1, 0}})
+	if err := group.Scale(nil, nil); err != nil {
+		t.Fatalf("Scale failed: %v", err)
+	}
+
+	if !(nodes[2].GetBindings().Min.X < nodes[1].GetBindings().Min.X && nodes[1].GetBindings().Min.X < nodes[0].GetBindings().Min.X) {
+		t.Errorf("expected layers from left to right along the links, got %v %v %v",
+			nodes[2].GetBindings(), nodes[1].GetBindings(), nodes[0].GetBindings())
+	}
+	if nodes[2].GetBindings().Min.Y != nodes[1].GetBindings().Min.Y || nodes[1].GetBindings().Min.Y != nodes[0].GetBindings().Min.Y {
+		t.Errorf("expected a chain on one row, got %v %v %v",
+			nodes[2].GetBindings(), nodes[1].GetBindings(), nodes[0].GetBindings())
+	}
+	if gap := nodes[1].GetBindings().Min.X - nodes[2].GetBindings().Max.X; gap != LAYERED_LAYER_GAP {
+		t.Errorf("expected gap %d between layers, got %d", LAYERED_LAYER_GAP, gap)
+	}
+}
+
+func TestLayeredLayoutFanOut(t *testing.T) {
+	group, nodes := newLayeredGroup(t, 3, [][2]int{{0, 1}, {0, 2}})
+	if err := group.Scale(nil, nil); err != nil {
+		t.Fatalf("Scale failed: %v", err)
+	}
+
+	b1, b2 := nodes[1].GetBindings(), nodes[2].GetBindings()
+	if b1.Min.X != b2.Min.X {
+		t.Errorf("expected targets on the same layer, got %v %v", b1, b2)
+	}
+	if b1.Overlaps(b2) {
+		t.Errorf("expected targets not to overlap, got %v %v", b1, b2)
+	}
+	center := (b1.Min.Y + b2.Max.Y) / 2
+	if source := nodes[0].GetBindings(); (source.Min.Y+source.Max.Y)/2 != center {
+		t.Errorf("expected source centered on its targets at %d, got %v", center, source)
+	}
+}
+
+func TestLayeredLayoutCycle(t *testing.T) {
+	group, nodes := newLayeredGroup(t, 2, [][2]int{{0, 1}, {1, 0}})
+	if err := group.Scale(nil, nil); err != nil {
+		t.Fatalf("Scale failed: %v", err)
+	}
+	if nodes[0].GetBindings().Min.X >= nodes[1].GetBindings().Min.X {
+		t.Errorf("expected the cycle to be broken in children order, got %v %v", nodes[0].GetBindings(), nodes[1].GetBindings())
+	}
+}
+
+func TestCountAllCrossings(t *testing.T) {
+	nodes := []*layeredNode{
+		{down: []int{3}},
+		{down: []int{2}},
+		{up: []int{1}},
+		{up: []int{0}},
+	}
+	if crossings := countAllCrossings(nodes, [][]int{{0, 1}, {2, 3}}); crossings != 1 {
+		t.Errorf("expected 1 crossing, got %d", crossings)
+	}
+	if crossings := countAllCrossings(nodes, [][]int{{0, 1}, {3, 2}}); crossings != 0 {
+		t.Errorf("expected no crossing, got %d", crossings)
+	}
+}
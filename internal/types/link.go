@@ -27,7 +27,7 @@ type LINK_LABEL_TYPE int
 
 const (
 	LINK_LABEL_TYPE_HORIZONTAL LINK_LABEL_TYPE = iota
-	// [TODO] LINK_LABEL_TYPE_ALONG_PATH
+	LINK_LABEL_TYPE_ALONG_PATH
 )
 
 type Link struct {
@@ -40,7 +40,17 @@ type Link struct {
 	Type            string
 	LineWidth       int
 	LineStyle       string
+	dashPattern     []float64
 	Labels          LinkLabels
+	obstacles       []*Resource
+	router          *Router
+	sourceAnchor    *Anchor
+	targetAnchor    *Anchor
+	waypoints       []Waypoint
+	lineJump        string
+	bundle          string
+	path            *linkPath
+	avoidHeaders    bool
 	drawn           bool
 	lineColor       color.RGBA
 }
@@ -50,6 +60,7 @@ type LinkLabels struct {
 	SourceLeft  *LinkLabel
 	TargetRight *LinkLabel
 	TargetLeft  *LinkLabel
+	Center      *LinkLabel
 }
 
 type LinkLabel struct {
@@ -104,48 +115,18 @@ func (l *Link) SetLineStyle(s string) {
 	l.LineStyle = s
 }
 
-func (l *Link) drawNeighborsDot(img *image.RGBA, x, y float64) {
-	lowerPt := image.Point{int(x), int(y)}
-
-	neighborPts := []image.Point{{0, 0}, {1, 0}, {0, 1}, {1, 1}}
-	for _, neighborPt := range neighborPts {
-		targetPt := lowerPt.Add(neighborPt)
-		sourceColor := img.At(targetPt.X, targetPt.Y)
-		dx := math.Abs(float64(targetPt.X) - x)
-		dy := math.Abs(float64(targetPt.Y) - y)
-		c := (1.0 - dx) * (1.0 - dy) * float64(l.lineColor.A)
-		targetColor := l.lineColor
-		targetColor.A = uint8(c)
-
-		img.Set(targetPt.X, targetPt.Y, _blend_color(sourceColor, targetColor))
-	}
+func (l *Link) SetObstacles(obstacles []*Resource) {
+	l.obstacles = obstacles
 }
 
+// drawLine draws a straight line with the width and the style of the link
 func (l *Link) drawLine(img *image.RGBA, sourcePt image.Point, targetPt image.Point) {
-	sourceVec := vector.New(float64(sourcePt.X), float64(sourcePt.Y))
-	targetVec := vector.New(float64(targetPt.X), float64(targetPt.Y))
-	direction := targetVec.Sub(sourceVec)
-	length := direction.Length()
-
-	if length == 0 {
-		return
-	}
-
-	unitDir := direction.Normalize()
-	perpDir := unitDir.Perpendicular()
-
-	for i := 0; i < int(length); i++ {
-		pos := sourceVec.Add(unitDir.Scale(float64(i)))
+	l.drawPath(img, []vector.Vector{toVector(sourcePt), toVector(targetPt)}, nil)
+}
 
-		if l.LineStyle == "dashed" && i%9 > 5 {
-			continue
-		}
-		for j := 0; j < l.LineWidth; j++ {
-			offset := float64(j) - float64(l.LineWidth-1)/2
-			finalPos := pos.Add(perpDir.Scale(offset))
-			l.drawNeighborsDot(img, finalPos.X, finalPos.Y)
-		}
-	}
+// strokeOutline draws a solid line through the points, for arrow heads and jumps
+func (l *Link) strokeOutline(img *image.RGBA, pts []vector.Vector, closed bool) {
+	fillPolygons(img, strokePolyline(pts, float64(l.LineWidth), JOIN_MITER, closed), l.lineColor)
 }
 
 func (l *Link) prepareFontFace(label *LinkLabel, parent1, parent2 *Resource) (font.Face, error) {
@@ -258,6 +239,10 @@ func (l *Link) drawLabel(img *image.RGBA, pos Windrose, source, target *Resource
 	if err != nil {
 		return fmt.Errorf("failed to prepare font face for link label: %w", err)
 	}
+	if label.Type == LINK_LABEL_TYPE_ALONG_PATH {
+		l.drawLabelAlongPath(img, sourcePt, targetPt, side, label, fontFace)
+		return nil
+	}
 	texts := strings.Split(label.Title, "\n")
 	for _, line := range texts {
 		textBindings, _ := font.BoundString(fontFace, line)
@@ -289,7 +274,7 @@ func (l *Link) drawLabel(img *image.RGBA, pos Windrose, source, target *Resource
 		lineOffset := fixed.I(0)
 		for _, line := range texts {
 			textBindings, _ := font.BoundString(fontFace, line)
-			point := fixed.Point26_6{fixed.I(int(l.X)), fixed.I(int(l.Y)) + lineOffset}
+			point := fixed.Point26_6{X: fixed.I(int(l.X)), Y: fixed.I(int(l.Y)) + lineOffset}
 			d := &font.Drawer{
 				Dst:  img,
 				Src:  image.NewUniform(label.Color),
@@ -321,9 +306,7 @@ func (l *Link) drawArrowHead(img *image.RGBA, arrowPt image.Point, originPt imag
 	direction := arrowVec.Sub(originVec)
 	length := direction.Length()
 
-	if arrowHead.Length == 0 {
-		arrowHead.Length = 10
-	}
+	arrowHead.Length = l.arrowHeadLength(arrowHead)
 	log.Infof("arrowHead.Length:\"%v\", arrowHead.Width:\"%v\"", arrowHead.Length, arrowHead.Width)
 	_a, _b, _c := l.getThreeSide(arrowHead.Width)
 
@@ -341,96 +324,84 @@ func (l *Link) drawArrowHead(img *image.RGBA, arrowPt image.Point, originPt imag
 		arrowHead.Length*(-_c*dx+_a*dy)/(_b*length),
 	))
 
-	// Convert to int with rounding for better symmetry
-	at1 := image.Point{int(math.Round(at1Vec.X)), int(math.Round(at1Vec.Y))}
-	at2 := image.Point{int(math.Round(at2Vec.X)), int(math.Round(at2Vec.Y))}
-
 	switch arrowHead.Type {
 	case "Default":
 		log.Info("Default Arrow Head drawing")
-		al := int(arrowHead.Length)
-		for i := 0; i < al; i++ {
-			a := arrowPt.Mul(i)
-			b := a.Add(at1.Mul(al - i)).Div(al)
-			c := a.Add(at2.Mul(al - i)).Div(al)
-			l.drawLine(img, b, c)
-		}
+		// The outline keeps the head as large as the line is wide around it
+		triangle := []vector.Vector{arrowVec, at1Vec, at2Vec}
+		fillPolygons(img, append(strokePolyline(triangle, float64(l.LineWidth), JOIN_MITER, true), polygon(triangle).clockwise()), l.lineColor)
 	case "Open":
 		log.Info("Open Arrow Head drawing")
-		l.drawLine(img, arrowPt, at1)
-		l.drawLine(img, arrowPt, at2)
+		l.strokeOutline(img, []vector.Vector{at1Vec, arrowVec, at2Vec}, false)
+	default:
+		if length != 0 {
+			l.drawArrowShape(img, arrowVec, direction.Scale(-1/length), arrowHead)
+		}
 	}
 }
 
 func (l *Link) Draw(img *image.RGBA) error {
-	source := *l.Source
-	target := *l.Target
 	if l.drawn {
 		log.Info("Link already drawn")
 		return nil
 	}
 
 	log.Info("Link Drawing")
-	sourcePt := l.calcPositionWithOffset(source.GetBindings(), l.SourcePosition, l.Source, true)
-	targetPt := l.calcPositionWithOffset(target.GetBindings(), l.TargetPosition, l.Target, false)
-
-	if l.Type == "" || l.Type == "straight" {
-		l.drawLine(img, sourcePt, targetPt)
-		l.drawArrowHead(img, sourcePt, targetPt, l.SourceArrowHead)
-		l.drawArrowHead(img, targetPt, sourcePt, l.TargetArrowHead)
-		if err := l.drawLabel(img, l.SourcePosition, l.Source, l.Target, sourcePt, targetPt, "Right", l.Labels.SourceRight); err != nil {
-			return fmt.Errorf("failed to draw source right label: %w", err)
-		}
-		if err := l.drawLabel(img, l.SourcePosition, l.Source, l.Target, sourcePt, targetPt, "Left", l.Labels.SourceLeft); err != nil {
-			return fmt.Errorf("failed to draw source left label: %w", err)
+	if l.path == nil {
+		if err := l.computePath(); err != nil {
+			return err
 		}
-		if err := l.drawLabel(img, l.TargetPosition, l.Target, l.Source, targetPt, sourcePt, "Left", l.Labels.TargetRight); err != nil {
-			return fmt.Errorf("failed to draw target right label: %w", err)
+	}
+	path := l.path
+	if path.curves != nil {
+		pts := flattenCurves(path.curves)
+		l.drawPath(img, pts, l.arrowHeadParts(polylineLength(pts)))
+
+		// Arrow heads and labels follow the tangents at the ends of the curve
+		c1 := path.curves[0][1]
+		c2 := path.curves[len(path.curves)-1][2]
+		sourceTangentPt := image.Point{int(math.Round(c1.X)), int(math.Round(c1.Y))}
+		targetTangentPt := image.Point{int(math.Round(c2.X)), int(math.Round(c2.Y))}
+		if err := l.drawEnds(img, path.sourcePt, sourceTangentPt, path.targetPt, targetTangentPt); err != nil {
+			return err
 		}
-		if err := l.drawLabel(img, l.TargetPosition, l.Target, l.Source, targetPt, sourcePt, "Right", l.Labels.TargetLeft); err != nil {
-			return fmt.Errorf("failed to draw target left label: %w", err)
+		if err := l.drawCenterLabel(img, sampleCurve(path.curves)); err != nil {
+			return err
 		}
-	} else if l.Type == "orthogonal" {
-		controlPts := l.calculateOrthogonalPath(sourcePt, targetPt)
-
-		// Draw the path
-		if len(controlPts) >= 1 {
-			l.drawLine(img, sourcePt, controlPts[0])
-			l.drawArrowHead(img, sourcePt, controlPts[0], l.SourceArrowHead)
-			if err := l.drawLabel(img, l.SourcePosition, l.Source, l.Target, sourcePt, controlPts[0], "Right", l.Labels.SourceRight); err != nil {
-				return fmt.Errorf("failed to draw source right label: %w", err)
-			}
-			if err := l.drawLabel(img, l.SourcePosition, l.Source, l.Target, sourcePt, controlPts[0], "Left", l.Labels.SourceLeft); err != nil {
-				return fmt.Errorf("failed to draw source left label: %w", err)
-			}
-			for i := 0; i < len(controlPts)-1; i++ {
-				l.drawLine(img, controlPts[i], controlPts[i+1])
-			}
-			l.drawLine(img, controlPts[len(controlPts)-1], targetPt)
-			l.drawArrowHead(img, targetPt, controlPts[len(controlPts)-1], l.TargetArrowHead)
-			if err := l.drawLabel(img, l.TargetPosition, l.Target, l.Source, targetPt, controlPts[len(controlPts)-1], "Left", l.Labels.TargetRight); err != nil {
-				return fmt.Errorf("failed to draw target right label: %w", err)
-			}
-			if err := l.drawLabel(img, l.TargetPosition, l.Target, l.Source, targetPt, controlPts[len(controlPts)-1], "Right", l.Labels.TargetLeft); err != nil {
-				return fmt.Errorf("failed to draw target left label: %w", err)
-			}
-		} else {
-			l.drawLine(img, sourcePt, targetPt)
-			l.drawArrowHead(img, sourcePt, targetPt, l.SourceArrowHead)
-			l.drawArrowHead(img, targetPt, sourcePt, l.TargetArrowHead)
-			if err := l.drawLabel(img, l.SourcePosition, l.Source, l.Target, sourcePt, targetPt, "Right", l.Labels.SourceRight); err != nil {
-				return fmt.Errorf("failed to draw source right label: %w", err)
-			}
-			if err := l.drawLabel(img, l.SourcePosition, l.Source, l.Target, sourcePt, targetPt, "Left", l.Labels.SourceLeft); err != nil {
-				return fmt.Errorf("failed to draw source left label: %w", err)
-			}
-			if err := l.drawLabel(img, l.TargetPosition, l.Target, l.Source, targetPt, sourcePt, "Left", l.Labels.TargetRight); err != nil {
-				return fmt.Errorf("failed to draw target right label: %w", err)
-			}
-			if err := l.drawLabel(img, l.TargetPosition, l.Target, l.Source, targetPt, sourcePt, "Right", l.Labels.TargetLeft); err != nil {
-				return fmt.Errorf("failed to draw target left label: %w", err)
+	} else if err := l.drawPolyline(img, path); err != nil {
+		return err
+	}
+	l.drawn = true
+	return nil
+}
+
+// computePath computes the geometry of the link once the resources are laid out. Links are
+// rasterized afterwards so that the crossings of all links are known.
+func (l *Link) computePath() error {
+	sourcePt := l.calcPositionWithOffset(l.Source.GetBindings(), l.SourcePosition, l.Source, true)
+	targetPt := l.calcPositionWithOffset(l.Target.GetBindings(), l.TargetPosition, l.Target, false)
+	path := &linkPath{sourcePt: sourcePt, targetPt: targetPt}
+
+	if l.Type == "" || l.Type == "straight" {
+		path.controlPts = l.waypointPoints()
+	} else if l.Type == "orthogonal" || l.Type == "orthogonal-routed" {
+		var controlPts []image.Point
+		routed := false
+		if len(l.waypoints) != 0 {
+			// Waypoints set by the author replace the automatic routing
+			controlPts = l.orthogonalWaypointPath(sourcePt, targetPt)
+			routed = true
+		} else if l.Type == "orthogonal-routed" {
+			controlPts, routed = l.route(sourcePt, targetPt)
+			if !routed {
+				log.Warnf("No route found for link from %s to %s, falling back to orthogonal", l.Source.label, l.Target.label)
 			}
 		}
+		if !routed {
+			controlPts = l.calculateOrthogonalPath(sourcePt, targetPt)
+			controlPts = l.avoidObstacles(sourcePt, targetPt, controlPts)
+		}
+		path.controlPts = controlPts
 
 		/* Original orthogonal implementation - commented out for reference
 		controlPts := []image.Point{}
@@ -475,10 +446,47 @@ func (l *Link) Draw(img *image.RGBA) error {
 			}
 		}
 		*/
+	} else if l.Type == "curved" {
+		path.curves = l.curveSegments(sourcePt, targetPt)
 	} else {
 		return fmt.Errorf("unknown link type: %s", l.Type)
 	}
-	l.drawn = true
+	l.path = path
+	return nil
+}
+
+// drawPolyline draws the link from its source to its target through the control points
+func (l *Link) drawPolyline(img *image.RGBA, path *linkPath) error {
+	pts := path.points()
+	vectors := []vector.Vector{}
+	for _, p := range pts {
+		vectors = append(vectors, toVector(p))
+	}
+	l.drawPath(img, vectors, l.hiddenParts(pts))
+	l.drawJumpArcs(img, pts)
+	if err := l.drawEnds(img, pts[0], pts[1], pts[len(pts)-1], pts[len(pts)-2]); err != nil {
+		return err
+	}
+	return l.drawCenterLabel(img, vectors)
+}
+
+// drawEnds draws the arrow heads and the labels of the link. sourceNextPt and targetNextPt are
+// the points the link heads to from its ends.
+func (l *Link) drawEnds(img *image.RGBA, sourcePt, sourceNextPt, targetPt, targetNextPt image.Point) error {
+	l.drawArrowHead(img, sourcePt, sourceNextPt, l.SourceArrowHead)
+	l.drawArrowHead(img, targetPt, targetNextPt, l.TargetArrowHead)
+	if err := l.drawLabel(img, l.SourcePosition, l.Source, l.Target, sourcePt, sourceNextPt, "Right", l.Labels.SourceRight); err != nil {
+		return fmt.Errorf("failed to draw source right label: %w", err)
+	}
+	if err := l.drawLabel(img, l.SourcePosition, l.Source, l.Target, sourcePt, sourceNextPt, "Left", l.Labels.SourceLeft); err != nil {
+		return fmt.Errorf("failed to draw source left label: %w", err)
+	}
+	if err := l.drawLabel(img, l.TargetPosition, l.Target, l.Source, targetPt, targetNextPt, "Left", l.Labels.TargetRight); err != nil {
+		return fmt.Errorf("failed to draw target right label: %w", err)
+	}
+	if err := l.drawLabel(img, l.TargetPosition, l.Target, l.Source, targetPt, targetNextPt, "Right", l.Labels.TargetLeft); err != nil {
+		return fmt.Errorf("failed to draw target left label: %w", err)
+	}
 	return nil
 }
 
@@ -898,6 +906,9 @@ func abs(x int) int {
 
 func (l *Link) calcPositionWithOffset(bindings image.Rectangle, position Windrose, resource *Resource, isSource bool) image.Point {
 	pt, _ := calcPosition(bindings, position)
+	if anchor := l.anchorOn(resource); anchor != nil {
+		pt = anchor.point(bindings)
+	}
 
 	// Check if grouping offset is enabled for this resource
 	if !resource.groupingOffset {
@@ -949,7 +960,7 @@ func (l *Link) getLinkIndexAndCount(resource *Resource, position Windrose) (int,
 			continue
 		}
 
-		if linkPosition == position {
+		if linkPosition == position && link.positionKey(resource) == l.positionKey(resource) {
 			if link == l {
 				index = count
 				log.Infof("Found current link at sorted index %d for position %v (unified count)", index, position)
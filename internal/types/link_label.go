@@ -0,0 +1,186 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package types
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"math"
+	"strings"
+
+	"github.com/awslabs/diagram-as-code/internal/vector"
+	"golang.org/x/image/font"
+	"golang.org/x/image/math/fixed"
+)
+
+const (
+	LINK_LABEL_MARGIN  = 5  // between a label and its link
+	LINK_LABEL_END_GAP = 10 // between a label along the path and the end of its link
+)
+
+// labelMask renders the lines of a label into an alpha mask
+func labelMask(face font.Face, title string) *image.Alpha {
+	lines := strings.Split(title, "\n")
+	width, height := 0, 0
+	for _, line := range lines {
+		textBindings, _ := font.BoundString(face, line)
+		width = max(width, textBindings.Max.X.Ceil()-textBindings.Min.X.Floor())
+		height += textBindings.Max.Y.Ceil() - textBindings.Min.Y.Floor()
+	}
+	mask := image.NewAlpha(image.Rect(0, 0, width, height))
+	y := 0
+	for _, line := range lines {
+		textBindings, _ := font.BoundString(face, line)
+		d := &font.Drawer{
+			Dst:  mask,
+			Src:  image.Opaque,
+			Face: face,
+			Dot:  fixed.Point26_6{X: -fixed.I(textBindings.Min.X.Floor()), Y: fixed.I(y - textBindings.Min.Y.Floor())},
+		}
+		d.DrawString(line)
+		y += textBindings.Max.Y.Ceil() - textBindings.Min.Y.Floor()
+	}
+	return mask
+}
+
+// drawMask draws the mask in color c centered on center, with its width along the unit vector dir
+func drawMask(img *image.RGBA, mask *image.Alpha, c color.RGBA, center, dir vector.Vector) {
+	w := float64(mask.Rect.Dx())
+	h := float64(mask.Rect.Dy())
+	normal := vector.New(-dir.Y, dir.X)
+
+	minX, minY := math.Inf(1), math.Inf(1)
+	maxX, maxY := math.Inf(-1), math.Inf(-1)
+	for _, sx := range []float64{-1, 1} {
+		for _, sy := range []float64{-1, 1} {
+			corner := center.Add(dir.Scale(sx * w / 2)).Add(normal.Scale(sy * h / 2))
+			minX, minY = math.Min(minX, corner.X), math.Min(minY, corner.Y)
+			maxX, maxY = math.Max(maxX, corner.X), math.Max(maxY, corner.Y)
+		}
+	}
+
+	// Bilinear sampling of the mask keeps rotated text smooth
+	alphaAt := func(x, y int) float64 {
+		if x < 0 || y < 0 || x >= mask.Rect.Dx() || y >= mask.Rect.Dy() {
+			return 0
+		}
+		return float64(mask.AlphaAt(x, y).A)
+	}
+	for y := int(math.Floor(minY)); y <= int(math.Ceil(maxY)); y++ {
+		for x := int(math.Floor(minX)); x <= int(math.Ceil(maxX)); x++ {
+			rel := vector.New(float64(x)+0.5, float64(y)+0.5).Sub(center)
+			u := rel.Dot(dir) + w/2 - 0.5
+			v := rel.Dot(normal) + h/2 - 0.5
+			u0, v0 := math.Floor(u), math.Floor(v)
+			fu, fv := u-u0, v-v0
+			a := alphaAt(int(u0), int(v0))*(1-fu)*(1-fv) +
+				alphaAt(int(u0)+1, int(v0))*fu*(1-fv) +
+				alphaAt(int(u0), int(v0)+1)*(1-fu)*fv +
+				alphaAt(int(u0)+1, int(v0)+1)*fu*fv
+			if a == 0 {
+				continue
+			}
+			target := c
+			target.A = uint8(a * float64(c.A) / 255)
+			img.Set(x, y, _blend_color(img.At(x, y), target))
+		}
+	}
+}
+
+// readable turns a direction so that text along it doesn't read upside down
+func readable(dir vector.Vector) vector.Vector {
+	if dir.X < 0 || (dir.X == 0 && dir.Y > 0) {
+		return dir.Scale(-1)
+	}
+	return dir
+}
+
+// drawLabelAlongPath draws a label of an end of the link rotated along the path. The label runs
+// from endPt towards nextPt on the given side of that direction.
+func (l *Link) drawLabelAlongPath(img *image.RGBA, endPt, nextPt image.Point, side string, label *LinkLabel, face font.Face) {
+	dir := toVector(nextPt).Sub(toVector(endPt))
+	if dir.IsZero() {
+		return
+	}
+	dir = dir.Normalize()
+	mask := labelMask(face, label.Title)
+	normal := vector.New(-dir.Y, dir.X)
+	if side == "Left" {
+		normal = normal.Scale(-1)
+	}
+	center := toVector(endPt).
+		Add(dir.Scale(LINK_LABEL_END_GAP + float64(mask.Rect.Dx())/2)).
+		Add(normal.Scale(LINK_LABEL_MARGIN + float64(mask.Rect.Dy())/2))
+	drawMask(img, mask, *label.Color, center, readable(dir))
+}
+
+// pathMidpoint returns the point halfway along the path and the direction of the path there
+func pathMidpoint(pts []vector.Vector) (vector.Vector, vector.Vector) {
+	length := 0.0
+	for i := 0; i+1 < len(pts); i++ {
+		length += pts[i+1].Sub(pts[i]).Length()
+	}
+	half := length / 2
+	for i := 0; i+1 < len(pts); i++ {
+		segment := pts[i+1].Sub(pts[i])
+		if segment.IsZero() {
+			continue
+		}
+		if half <= segment.Length() || i+2 == len(pts) {
+			dir := segment.Normalize()
+			return pts[i].Add(dir.Scale(math.Min(half, segment.Length()))), dir
+		}
+		half -= segment.Length()
+	}
+	if len(pts) == 0 {
+		return vector.New(0, 0), vector.New(1, 0)
+	}
+	return pts[0], vector.New(1, 0)
+}
+
+// drawCenterLabel draws the Center label at the middle of the path, above the path or on its
+// right when the path is vertical.
+func (l *Link) drawCenterLabel(img *image.RGBA, path []vector.Vector) error {
+	label := l.Labels.Center
+	if label == nil {
+		return nil
+	}
+	face, err := l.prepareFontFace(label, l.Source, l.Target)
+	if err != nil {
+		return fmt.Errorf("failed to prepare font face for link label: %w", err)
+	}
+	mask := labelMask(face, label.Title)
+	w := float64(mask.Rect.Dx())
+	h := float64(mask.Rect.Dy())
+
+	mid, dir := pathMidpoint(path)
+	normal := vector.New(dir.Y, -dir.X)
+	if normal.Y > 0 || (normal.Y == 0 && normal.X < 0) {
+		normal = normal.Scale(-1)
+	}
+	if label.Type == LINK_LABEL_TYPE_ALONG_PATH {
+		drawMask(img, mask, *label.Color, mid.Add(normal.Scale(LINK_LABEL_MARGIN+h/2)), readable(dir))
+		return nil
+	}
+	// The distance from the center of the box to its side facing the path
+	extent := math.Abs(normal.X)*w/2 + math.Abs(normal.Y)*h/2
+	drawMask(img, mask, *label.Color, mid.Add(normal.Scale(LINK_LABEL_MARGIN+extent)), vector.New(1, 0))
+	return nil
+}
+
+// sampleCurve returns points along the curves to measure them as a polyline
+func sampleCurve(segments [][4]vector.Vector) []vector.Vector {
+	const steps = 32
+	pts := []vector.Vector{}
+	for _, c := range segments {
+		for s := 0; s <= steps; s++ {
+			if s == 0 && len(pts) != 0 {
+				continue
+			}
+			pts = append(pts, cubicBezier(c[0], c[1], c[2], c[3], float64(s)/steps))
+		}
+	}
+	return pts
+}
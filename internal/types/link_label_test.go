@@ -0,0 +1,85 @@
+package types
+
+import (
+	"image"
+	"image/color"
+	"testing"
+
+	"github.com/awslabs/diagram-as-code/internal/vector"
+)
+
+func TestPathMidpoint(t *testing.T) {
+	pts := []vector.Vector{vector.New(0, 0), vector.New(100, 0), vector.New(100, 100), vector.New(200, 100)}
+	mid, dir := pathMidpoint(pts)
+	if mid != vector.New(100, 50) || dir != vector.New(0, 1) {
+		t.Errorf("expected the midpoint (100,50) heading south, got %v heading %v", mid, dir)
+	}
+	mid, dir = pathMidpoint([]vector.Vector{vector.New(0, 0), vector.New(40, 0)})
+	if mid != vector.New(20, 0) || dir != vector.New(1, 0) {
+		t.Errorf("expected the midpoint (20,0) heading east, got %v heading %v", mid, dir)
+	}
+}
+
+func TestReadable(t *testing.T) {
+	tests := []struct {
+		dir, expected vector.Vector
+	}{
+		{vector.New(1, 0), vector.New(1, 0)},
+		{vector.New(-1, 0), vector.New(1, 0)},
+		{vector.New(0, 1), vector.New(0, -1)},
+		{vector.New(0, -1), vector.New(0, -1)},
+	}
+	for _, tt := range tests {
+		if d := readable(tt.dir); d != tt.expected {
+			t.Errorf("expected %v for %v, got %v", tt.expected, tt.dir, d)
+		}
+	}
+}
+
+func TestDrawMask(t *testing.T) {
+	mask := image.NewAlpha(image.Rect(0, 0, 40, 10))
+	for x := 0; x < 40; x++ {
+		for y := 0; y < 10; y++ {
+			mask.Pix[y*mask.Stride+x] = 255
+		}
+	}
+	bounds := func(dir vector.Vector) image.Rectangle {
+		img := image.NewRGBA(image.Rect(0, 0, 100, 100))
+		drawMask(img, mask, color.RGBA{0, 0, 0, 255}, vector.New(50, 50), dir)
+		b := image.Rectangle{}
+		for y := 0; y < 100; y++ {
+			for x := 0; x < 100; x++ {
+				if img.RGBAAt(x, y).A > 128 {
+					b = b.Union(image.Rect(x, y, x+1, y+1))
+				}
+			}
+		}
+		return b
+	}
+	if b := bounds(vector.New(1, 0)); b != image.Rect(30, 45, 70, 55) {
+		t.Errorf("expected the mask at (30,45)-(70,55), got %v", b)
+	}
+	if b := bounds(vector.New(0, -1)); b != image.Rect(45, 30, 55, 70) {
+		t.Errorf("expected the rotated mask at (45,30)-(55,70), got %v", b)
+	}
+}
+
+func TestDrawCenterLabel(t *testing.T) {
+	link := Link{}.Init(new(Resource).Init(), WINDROSE_E, ArrowHead{}, new(Resource).Init(), WINDROSE_W, ArrowHead{}, 2, color.RGBA{0, 0, 0, 255})
+	link.Labels.Center = &LinkLabel{Title: "gRPC", Font: "goregular"}
+	img := image.NewRGBA(image.Rect(0, 0, 200, 100))
+	if err := link.drawCenterLabel(img, []vector.Vector{vector.New(0, 60), vector.New(200, 60)}); err != nil {
+		t.Fatalf("drawCenterLabel failed: %v", err)
+	}
+	drawn := image.Rectangle{}
+	for y := 0; y < 100; y++ {
+		for x := 0; x < 200; x++ {
+			if img.RGBAAt(x, y).A != 0 {
+				drawn = drawn.Union(image.Rect(x, y, x+1, y+1))
+			}
+		}
+	}
+	if drawn.Empty() || drawn.Max.Y > 60-LINK_LABEL_MARGIN || (drawn.Min.X+drawn.Max.X)/2 < 95 || (drawn.Min.X+drawn.Max.X)/2 > 105 {
+		t.Errorf("expected the label centered above the path, got %v", drawn)
+	}
+}
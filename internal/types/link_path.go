@@ -0,0 +1,227 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package types
+
+import (
+	"image"
+	"math"
+	"sort"
+
+	"github.com/awslabs/diagram-as-code/internal/vector"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	LINE_JUMP_NONE = ""
+	LINE_JUMP_ARC  = "arc" // a small half circle over the other link
+	LINE_JUMP_GAP  = "gap" // an interruption of the line
+
+	LINE_JUMP_RADIUS = 6 // half the length of a jump, not counting the width of the other link
+)
+
+// linkPath is the geometry of a link: a polyline through the control points, or curves
+type linkPath struct {
+	sourcePt   image.Point
+	targetPt   image.Point
+	controlPts []image.Point
+	curves     [][4]vector.Vector
+	jumps      map[int][]float64  // distances of the jumps from the start of each segment
+	covered    map[int][2]float64 // part of each segment drawn by another link of its bundle
+}
+
+func (p *linkPath) points() []image.Point {
+	return append(append([]image.Point{p.sourcePt}, p.controlPts...), p.targetPt)
+}
+
+// segments returns the path as straight segments, sampling the curves
+func (p *linkPath) segments() [][2]vector.Vector {
+	pts := []vector.Vector{}
+	if p.curves != nil {
+		pts = sampleCurve(p.curves)
+	} else {
+		for _, pt := range p.points() {
+			pts = append(pts, toVector(pt))
+		}
+	}
+	segments := [][2]vector.Vector{}
+	for i := 0; i+1 < len(pts); i++ {
+		segments = append(segments, [2]vector.Vector{pts[i], pts[i+1]})
+	}
+	return segments
+}
+
+func (l *Link) SetLineJump(jump string) {
+	l.lineJump = jump
+}
+
+// prepareLinks computes the paths of the links in the order Draw draws them, since the order
+// decides the offsets of links sharing a position and the routes of routed links. It returns
+// the links in that order.
+func (r *Resource) prepareLinks(prepared map[*Resource]bool, links []*Link) []*Link {
+	if r.divider {
+		prepared[r] = true
+		return links
+	}
+	for _, subResource := range r.children {
+		links = subResource.prepareLinks(prepared, links)
+	}
+	for _, borderResource := range r.borderChildren {
+		links = borderResource.Resource.prepareLinks(prepared, links)
+	}
+	for _, overlay := range r.overlays {
+		links = overlay.prepareLinks(prepared, links)
+	}
+	prepared[r] = true
+
+	r.sortAllLinks()
+	for _, link := range r.links {
+		if link.path != nil || !prepared[link.Source] || !prepared[link.Target] {
+			continue
+		}
+		if err := link.computePath(); err != nil {
+			// Draw reports the error
+			continue
+		}
+		links = append(links, link)
+	}
+	return links
+}
+
+// findLineJumps records where the links cross. A link drawn later jumps over the links drawn
+// before it, unless it doesn't jump and the earlier one does. Only straight segments jump.
+func findLineJumps(links []*Link) {
+	for j, later := range links {
+		for _, earlier := range links[:j] {
+			jumper, other := later, earlier
+			if jumper.lineJump == LINE_JUMP_NONE || jumper.path.curves != nil {
+				jumper, other = earlier, later
+			}
+			if jumper.lineJump == LINE_JUMP_NONE || jumper.path.curves != nil {
+				continue
+			}
+			for i, s := range jumper.path.segments() {
+				length := s[1].Sub(s[0]).Length()
+				radius := float64(LINE_JUMP_RADIUS + other.LineWidth)
+				for _, o := range other.path.segments() {
+					d, ok := segmentCrossing(s[0], s[1], o[0], o[1])
+					// Crossings close to a bend or an end can't hold a jump
+					if !ok || d < radius || d > length-radius {
+						continue
+					}
+					if jumper.path.jumps == nil {
+						jumper.path.jumps = map[int][]float64{}
+					}
+					jumper.path.jumps[i] = append(jumper.path.jumps[i], d)
+				}
+			}
+		}
+	}
+	for _, link := range links {
+		for i, jumps := range link.path.jumps {
+			sort.Float64s(jumps)
+			// Jumps overlapping each other are merged into the first one
+			merged := []float64{}
+			for _, d := range jumps {
+				if len(merged) != 0 && d-merged[len(merged)-1] < 2*LINE_JUMP_RADIUS+float64(link.LineWidth) {
+					continue
+				}
+				merged = append(merged, d)
+			}
+			link.path.jumps[i] = merged
+			log.Infof("Line jumps on segment %d: %v", i, merged)
+		}
+	}
+}
+
+// segmentCrossing returns the distance from a1 where the segment a1-a2 properly crosses b1-b2
+func segmentCrossing(a1, a2, b1, b2 vector.Vector) (float64, bool) {
+	da := a2.Sub(a1)
+	db := b2.Sub(b1)
+	denominator := da.X*db.Y - da.Y*db.X
+	if math.Abs(denominator) < 1e-9 {
+		// Parallel segments don't cross
+		return 0, false
+	}
+	diff := b1.Sub(a1)
+	t := (diff.X*db.Y - diff.Y*db.X) / denominator
+	u := (diff.X*da.Y - diff.Y*da.X) / denominator
+	if t <= 0 || t >= 1 || u <= 0 || u >= 1 {
+		return 0, false
+	}
+	return t * da.Length(), true
+}
+
+// hiddenParts returns the parts of the polyline through pts that aren't drawn as distances from
+// its start: the parts another link of its bundle draws, the jumps and the parts hidden by open
+// arrow heads
+func (l *Link) hiddenParts(pts []image.Point) [][2]float64 {
+	hidden := [][2]float64{}
+	radius := float64(LINE_JUMP_RADIUS + l.LineWidth)
+	start := 0.0
+	for i := 0; i+1 < len(pts); i++ {
+		if covered, ok := l.path.covered[i]; ok {
+			hidden = append(hidden, [2]float64{start + covered[0], start + covered[1]})
+		}
+		for _, d := range l.path.jumps[i] {
+			hidden = append(hidden, [2]float64{start + d - radius, start + d + radius})
+		}
+		start += segmentLength(pts[i], pts[i+1])
+	}
+	return append(hidden, l.arrowHeadParts(start)...)
+}
+
+// drawPath strokes the path through pts with the width and the style of the link, but the hidden
+// parts. Dashes run on past the hidden parts and around the bends.
+func (l *Link) drawPath(img *image.RGBA, pts []vector.Vector, hidden [][2]float64) {
+	sort.Slice(hidden, func(a, b int) bool { return hidden[a][0] < hidden[b][0] })
+	length := polylineLength(pts)
+	visible := [][2]float64{}
+	from := 0.0
+	for _, h := range hidden {
+		if h[0] > from {
+			visible = append(visible, [2]float64{from, math.Min(h[0], length)})
+		}
+		from = math.Max(from, h[1])
+	}
+	if from < length {
+		visible = append(visible, [2]float64{from, length})
+	}
+
+	pattern := l.linePattern()
+	polygons := []polygon{}
+	for _, part := range visible {
+		for _, dash := range dashPolyline(subPolyline(pts, part[0], part[1]), pattern, part[0]) {
+			polygons = append(polygons, strokePolyline(dash, float64(l.LineWidth), JOIN_MITER, false)...)
+		}
+	}
+	fillPolygons(img, polygons, l.lineColor)
+}
+
+// drawJumpArcs draws a half circle over every jump of the polyline through pts, above the
+// segment or on its right when the segment is vertical
+func (l *Link) drawJumpArcs(img *image.RGBA, pts []image.Point) {
+	if l.lineJump != LINE_JUMP_ARC {
+		return
+	}
+	radius := float64(LINE_JUMP_RADIUS + l.LineWidth)
+	for i := 0; i+1 < len(pts); i++ {
+		start := toVector(pts[i])
+		dir := toVector(pts[i+1]).Sub(start).Normalize()
+		normal := vector.New(dir.Y, -dir.X)
+		if normal.Y > 0 || (normal.Y == 0 && normal.X < 0) {
+			normal = normal.Scale(-1)
+		}
+		for _, d := range l.path.jumps[i] {
+			center := start.Add(dir.Scale(d))
+			steps := maxInt(8, int(math.Ceil(math.Pi*radius)))
+			arc := []vector.Vector{}
+			for s := 0; s <= steps; s++ {
+				angle := math.Pi * float64(s) / float64(steps)
+				// From the start of the jump over to its end
+				arc = append(arc, center.Add(dir.Scale(-radius*math.Cos(angle))).Add(normal.Scale(radius*math.Sin(angle))))
+			}
+			l.strokeOutline(img, arc, false)
+		}
+	}
+}
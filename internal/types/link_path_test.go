@@ -0,0 +1,68 @@
+package types
+
+import (
+	"image"
+	"image/color"
+	"testing"
+
+	"github.com/awslabs/diagram-as-code/internal/vector"
+)
+
+func TestSegmentCrossing(t *testing.T) {
+	d, ok := segmentCrossing(vector.New(0, 50), vector.New(100, 50), vector.New(30, 0), vector.New(30, 100))
+	if !ok || d != 30 {
+		t.Errorf("expected a crossing at 30, got %v %v", d, ok)
+	}
+	if _, ok := segmentCrossing(vector.New(0, 50), vector.New(100, 50), vector.New(0, 60), vector.New(100, 60)); ok {
+		t.Error("expected no crossing of parallel segments")
+	}
+	if _, ok := segmentCrossing(vector.New(0, 50), vector.New(100, 50), vector.New(30, 0), vector.New(30, 50)); ok {
+		t.Error("expected no crossing of a segment ending on the other")
+	}
+}
+
+func TestFindLineJumps(t *testing.T) {
+	newLink := func(jump string, from, to image.Point) *Link {
+		link := Link{}.Init(new(Resource).Init(), WINDROSE_E, ArrowHead{}, new(Resource).Init(), WINDROSE_W, ArrowHead{}, 2, color.RGBA{0, 0, 0, 255})
+		link.SetLineJump(jump)
+		link.path = &linkPath{sourcePt: from, targetPt: to}
+		return link
+	}
+	vertical := newLink(LINE_JUMP_ARC, image.Pt(50, 0), image.Pt(50, 100))
+	horizontal := newLink(LINE_JUMP_ARC, image.Pt(0, 40), image.Pt(100, 40))
+	plain := newLink(LINE_JUMP_NONE, image.Pt(0, 70), image.Pt(100, 70))
+	findLineJumps([]*Link{vertical, horizontal, plain})
+
+	// The later link jumps, unless it doesn't jump at all
+	if jumps := horizontal.path.jumps[0]; len(jumps) != 1 || jumps[0] != 50 {
+		t.Errorf("expected the horizontal link to jump at 50, got %v", horizontal.path.jumps)
+	}
+	if jumps := vertical.path.jumps[0]; len(jumps) != 1 || jumps[0] != 70 {
+		t.Errorf("expected the vertical link to jump over the plain link at 70, got %v", vertical.path.jumps)
+	}
+	if plain.path.jumps != nil {
+		t.Errorf("expected no jump on the plain link, got %v", plain.path.jumps)
+	}
+}
+
+func TestDrawSegmentWithGap(t *testing.T) {
+	link := Link{}.Init(new(Resource).Init(), WINDROSE_E, ArrowHead{}, new(Resource).Init(), WINDROSE_W, ArrowHead{}, 1, color.RGBA{0, 0, 0, 255})
+	link.SetLineJump(LINE_JUMP_GAP)
+	link.path = &linkPath{sourcePt: image.Pt(0, 10), targetPt: image.Pt(100, 10), jumps: map[int][]float64{0: {50}}}
+	img := image.NewRGBA(image.Rect(0, 0, 100, 20))
+	link.drawPolyline(img, link.path)
+	if img.RGBAAt(50, 10).A != 0 {
+		t.Error("expected a gap at the crossing")
+	}
+	if img.RGBAAt(20, 10).A == 0 || img.RGBAAt(80, 10).A == 0 {
+		t.Error("expected the line on both sides of the gap")
+	}
+
+	link.SetLineJump(LINE_JUMP_ARC)
+	link.path = &linkPath{sourcePt: image.Pt(0, 15), targetPt: image.Pt(100, 15), jumps: map[int][]float64{0: {50}}}
+	img = image.NewRGBA(image.Rect(0, 0, 100, 20))
+	link.drawPolyline(img, link.path)
+	if img.RGBAAt(50, 15).A != 0 || img.RGBAAt(50, 15-LINE_JUMP_RADIUS-1).A == 0 {
+		t.Error("expected an arc above the crossing")
+	}
+}
@@ -70,7 +70,7 @@ func TestGetThreeSide(t *testing.T) {
 	}
 }
 
-func TestDrawNeighborsDots1(t *testing.T) {
+func TestDrawLine(t *testing.T) {
 	// Create a test image
 	width, height := 10, 10
 	img := image.NewRGBA(image.Rect(0, 0, width, height))
@@ -81,20 +81,22 @@ func TestDrawNeighborsDots1(t *testing.T) {
 	}
 
 	// Set up a test link
-	link := &Link{lineColor: color.RGBA{255, 0, 0, 255}}
+	link := &Link{LineWidth: 1, lineColor: color.RGBA{255, 0, 0, 255}}
 
-	// Draw a dot at the center
-	x, y := float64(width/2), float64(height/2)
-	link.drawNeighborsDot(img, x, y)
+	// Draw a line through the centers of a row of pixels
+	link.drawLine(img, image.Point{1, height / 2}, image.Point{width - 2, height / 2})
 
-	// Check if the center pixel and its neighbors are set correctly
-	centerColor := img.At(width/2, height/2).(color.RGBA)
-	if centerColor != (color.RGBA{255, 0, 0, 255}) {
-		t.Errorf("Expected center pixel to be red, got %v", centerColor)
+	if c := img.RGBAAt(width/2, height/2); c != (color.RGBA{255, 0, 0, 255}) {
+		t.Errorf("Expected the pixel on the line to be red, got %v", c)
+	}
+	for _, p := range []image.Point{{width / 2, height/2 - 1}, {width / 2, height/2 + 1}, {0, height / 2}} {
+		if c := img.RGBAAt(p.X, p.Y); c != (color.RGBA{255, 255, 255, 255}) {
+			t.Errorf("Expected pixel at (%d, %d) to stay white, got %v", p.X, p.Y, c)
+		}
 	}
 }
 
-func TestDrawNeighborDots2(t *testing.T) {
+func TestDrawLineBetweenPixels(t *testing.T) {
 	// Create a test image
 	width, height := 9, 9
 	img := image.NewRGBA(image.Rect(0, 0, width, height))
@@ -105,23 +107,15 @@ func TestDrawNeighborDots2(t *testing.T) {
 	}
 
 	// Set up a test link
-	link := &Link{lineColor: color.RGBA{255, 0, 0, 255}}
-
-	// Draw a dot at the center
-	x, y := float64(width)/2.0, float64(height)/2.0
-	link.drawNeighborsDot(img, x, y)
-
-	// Check neighbors
-	neighbors := []image.Point{
-		{width / 2, height / 2},
-		{width/2 + 1, height / 2},
-		{width / 2, height/2 + 1},
-		{width/2 + 1, height/2 + 1},
-	}
-	for _, neighbor := range neighbors {
-		neighborColor := img.At(neighbor.X, neighbor.Y).(color.RGBA)
-		if neighborColor != (color.RGBA{255, 192, 192, 255}) {
-			t.Errorf("Expected neighbor pixel at (%d, %d) to be semi-transparent red, got %v", neighbor.X, neighbor.Y, neighborColor)
+	link := &Link{LineWidth: 1, lineColor: color.RGBA{255, 0, 0, 255}}
+
+	// A line between two rows of pixels covers half of each
+	link.drawPath(img, []vector.Vector{vector.New(0, 4.5), vector.New(8, 4.5)}, nil)
+
+	for _, y := range []int{4, 5} {
+		c := img.RGBAAt(width/2, y)
+		if c.R != 255 || c.G < 120 || c.G > 135 || c.G != c.B {
+			t.Errorf("Expected pixel at (%d, %d) to be half red, got %v", width/2, y, c)
 		}
 	}
 }
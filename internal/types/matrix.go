@@ -0,0 +1,198 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package types
+
+import (
+	"fmt"
+	"image"
+
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	MATRIX_COLUMN_PADDING = 20 // between an availability zone border and its subnets
+	MATRIX_COLUMN_GAP     = 30 // between two availability zones
+)
+
+type matrixKey struct {
+	row      int
+	column   int
+	straddle bool
+}
+
+type matrixCell struct {
+	gridCell
+	straddle bool
+	members  []*Resource
+	size     image.Point
+}
+
+// layoutMatrix places the already scaled children of a VPC at the intersection of their
+// availability zone (column) and tier (row). Availability zones are stretched to the whole
+// height as columns, and children without an availability zone straddle all columns.
+func (r *Resource) layoutMatrix() error {
+	referenced := map[*Resource]bool{}
+	for _, child := range r.layoutChildren() {
+		if child.matrixColumn != nil {
+			referenced[child.matrixColumn] = true
+		}
+	}
+
+	columns := []*Resource{}
+	columnIndex := map[*Resource]int{}
+	others := []*Resource{}
+	for _, child := range r.layoutChildren() {
+		if referenced[child] {
+			if len(child.children) != 0 {
+				return fmt.Errorf("availability zone %s in the az-matrix on %s cannot have children, set AvailabilityZone on its subnets instead", child.label, r.label)
+			}
+			columnIndex[child] = len(columns)
+			columns = append(columns, child)
+		} else {
+			others = append(others, child)
+		}
+	}
+	for az := range referenced {
+		if _, ok := columnIndex[az]; !ok {
+			return fmt.Errorf("availability zone %s is not a child of %s", az.label, r.label)
+		}
+	}
+
+	tierIndex := map[string]int{}
+	for _, tier := range r.tiers {
+		if _, ok := tierIndex[tier]; !ok {
+			tierIndex[tier] = len(tierIndex)
+		}
+	}
+	for _, child := range others {
+		if _, ok := tierIndex[child.tier]; !ok {
+			tierIndex[child.tier] = len(tierIndex)
+		}
+	}
+	log.Infof("Matrix %s: %d availability zones x %d tiers", r.label, len(columns), len(tierIndex))
+
+	// Group the children sharing a cell; children of a column cell are stacked vertically,
+	// the ones straddling the columns are lined up horizontally.
+	columnCount := maxInt(len(columns), 1)
+	matrixCells := map[matrixKey]*matrixCell{}
+	cellOf := map[*Resource]*matrixCell{}
+	cells := []gridCell{}
+	for _, child := range others {
+		key := matrixKey{row: tierIndex[child.tier], straddle: child.matrixColumn == nil}
+		if !key.straddle {
+			key.column = columnIndex[child.matrixColumn]
+		}
+		cell, ok := matrixCells[key]
+		if !ok {
+			cell = &matrixCell{
+				gridCell: gridCell{
+					resource:   child,
+					row:        key.row,
+					column:     key.column,
+					rowSpan:    1,
+					columnSpan: 1,
+				},
+				straddle: key.straddle,
+			}
+			if key.straddle {
+				cell.columnSpan = columnCount
+			}
+			matrixCells[key] = cell
+			cellOf[child] = cell
+			cells = append(cells, cell.gridCell)
+		}
+		cell.members = append(cell.members, child)
+		m := child.GetMargin()
+		b := child.GetBindings()
+		s := image.Point{b.Dx() + m.Left + m.Right, b.Dy() + m.Top + m.Bottom}
+		if cell.straddle {
+			cell.size = image.Point{cell.size.X + s.X, maxInt(cell.size.Y, s.Y)}
+		} else {
+			cell.size = image.Point{maxInt(cell.size.X, s.X), cell.size.Y + s.Y}
+		}
+	}
+
+	step := 2*MATRIX_COLUMN_PADDING + MATRIX_COLUMN_GAP
+	columnWidths := gridTrackSizes(columnCount, cells,
+		func(c gridCell) int { return c.column },
+		func(c gridCell) int { return c.columnSpan },
+		func(c gridCell) int { return cellOf[c.resource].size.X - (c.columnSpan-1)*step },
+	)
+	// Availability zones share one width so that straddling resources sit on their boundaries
+	widest := 0
+	for _, w := range columnWidths {
+		widest = maxInt(widest, w)
+	}
+	for i := range columnWidths {
+		columnWidths[i] = widest
+	}
+	rowHeights := gridTrackSizes(len(tierIndex), cells,
+		func(c gridCell) int { return c.row },
+		func(c gridCell) int { return c.rowSpan },
+		func(c gridCell) int { return cellOf[c.resource].size.Y },
+	)
+
+	headerHeight := 0
+	for _, az := range columns {
+		fontFace, err := az.prepareFontFace(false, r)
+		if err != nil {
+			return fmt.Errorf("failed to prepare font face: %w", err)
+		}
+		_, textHeight := az.labelSize(fontFace)
+		headerHeight = maxInt(headerHeight, textHeight+10)
+	}
+
+	columnX := make([]int, columnCount+1)
+	columnX[0] = MATRIX_COLUMN_PADDING
+	for i, w := range columnWidths {
+		columnX[i+1] = columnX[i] + w + step
+	}
+	rowY := make([]int, len(rowHeights)+1)
+	rowY[0] = headerHeight
+	for i, h := range rowHeights {
+		rowY[i+1] = rowY[i] + h
+	}
+
+	for _, gc := range cells {
+		cell := cellOf[gc.resource]
+		x1 := columnX[cell.column]
+		x2 := columnX[cell.column+cell.columnSpan] - step
+		y1, y2 := rowY[cell.row], rowY[cell.row+1]
+		x := x1 + (x2-x1-cell.size.X)/2
+		y := y1 + (y2-y1-cell.size.Y)/2
+		for _, child := range cell.members {
+			bindings := child.GetBindings()
+			margin := child.GetMargin()
+			width := bindings.Dx() + margin.Left + margin.Right
+			height := bindings.Dy() + margin.Top + margin.Bottom
+			dx := x + margin.Left - bindings.Min.X
+			dy := y + margin.Top - bindings.Min.Y
+			if cell.straddle {
+				dy += (cell.size.Y - height) / 2
+				x += width
+			} else {
+				dx += (cell.size.X - width) / 2
+				y += height
+			}
+			if err := child.Translation(dx, dy); err != nil {
+				return fmt.Errorf("failed to translate matrix cell: %w", err)
+			}
+		}
+	}
+
+	for i, az := range columns {
+		az.SetBindings(image.Rect(
+			columnX[i]-MATRIX_COLUMN_PADDING,
+			0,
+			columnX[i+1]-step+MATRIX_COLUMN_PADDING,
+			rowY[len(rowY)-1]+MATRIX_COLUMN_PADDING,
+		))
+		az.SetMargin(Margin{0, 0, 0, 0})
+	}
+
+	// Draw the availability zones first so that they stay behind the subnets, keeping the order of
+	// the children as declared
+	r.drawOrder = append(append(columns, others...), r.pinnedChildren()...)
+	return nil
+}
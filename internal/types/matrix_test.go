@@ -0,0 +1,96 @@
+package types
+
+import (
+	"image"
+	"strings"
+	"testing"
+)
+
+func TestMatrixLayout(t *testing.T) {
+	vpc := new(Resource).Init()
+	vpc.SetDirection("az-matrix")
+	vpc.SetTiers([]string{"public", "private"})
+	az1 := new(Resource).Init()
+	az2 := new(Resource).Init()
+	public1 := newGridCell(100, 50)
+	public1.SetMatrixCell(az1, "public")
+	private1 := newGridCell(100, 80)
+	private1.SetMatrixCell(az1, "private")
+	private2 := newGridCell(60, 40)
+	private2.SetMatrixCell(az2, "private")
+	alb := newGridCell(64, 64)
+	alb.SetMatrixCell(nil, "private")
+	// Children are listed out of order on purpose
+	for _, c := range []*Resource{private1, az1, public1, alb, az2, private2} {
+		if err := vpc.AddChild(c); err != nil {
+			t.Fatalf("AddChild failed: %v", err)
+		}
+	}
+	if err := vpc.Scale(nil, nil); err != nil {
+		t.Fatalf("Scale failed: %v", err)
+	}
+
+	if vpc.drawnChildren()[0] != az1 || vpc.drawnChildren()[1] != az2 {
+		t.Error("availability zones should be drawn first")
+	}
+	if vpc.children[0] != private1 || vpc.children[1] != az1 {
+		t.Error("the children should keep their declared order")
+	}
+
+	// Both availability zones are 140px wide and stretched to the whole height
+	az1Bindings := az1.GetBindings()
+	az2Bindings := az2.GetBindings()
+	if az1Bindings.Dx() != 140 || az2Bindings.Dx() != 140 {
+		t.Errorf("expected availability zones to be 140px wide, got %v and %v", az1Bindings, az2Bindings)
+	}
+	if az1Bindings.Min.Y != az2Bindings.Min.Y || az1Bindings.Max.Y != az2Bindings.Max.Y {
+		t.Errorf("expected availability zones to have the same height, got %v and %v", az1Bindings, az2Bindings)
+	}
+	if az2Bindings.Min.X-az1Bindings.Max.X != MATRIX_COLUMN_GAP {
+		t.Errorf("expected gap %d between availability zones, got %d", MATRIX_COLUMN_GAP, az2Bindings.Min.X-az1Bindings.Max.X)
+	}
+
+	// Subnets are inside their column and in their tier row
+	for _, tc := range []struct {
+		name   string
+		subnet *Resource
+		az     image.Rectangle
+	}{
+		{"public1", public1, az1Bindings},
+		{"private1", private1, az1Bindings},
+		{"private2", private2, az2Bindings},
+	} {
+		if !tc.subnet.GetBindings().In(tc.az) {
+			t.Errorf("%s: expected %v inside %v", tc.name, tc.subnet.GetBindings(), tc.az)
+		}
+	}
+	if public1.GetBindings().Max.Y > private1.GetBindings().Min.Y {
+		t.Errorf("expected public tier above private tier, got %v and %v", public1.GetBindings(), private1.GetBindings())
+	}
+
+	// The cross-AZ resource straddles the two columns
+	center := (alb.GetBindings().Min.X + alb.GetBindings().Max.X) / 2
+	if center != (az1Bindings.Max.X+az2Bindings.Min.X)/2 {
+		t.Errorf("expected %v to straddle %v and %v", alb.GetBindings(), az1Bindings, az2Bindings)
+	}
+}
+
+func TestMatrixLayoutAvailabilityZoneWithChildren(t *testing.T) {
+	vpc := new(Resource).Init()
+	vpc.SetDirection("az-matrix")
+	az := new(Resource).Init()
+	if err := az.AddChild(newGridCell(10, 10)); err != nil {
+		t.Fatalf("AddChild failed: %v", err)
+	}
+	subnet := newGridCell(10, 10)
+	subnet.SetMatrixCell(az, "public")
+	for _, c := range []*Resource{az, subnet} {
+		if err := vpc.AddChild(c); err != nil {
+			t.Fatalf("AddChild failed: %v", err)
+		}
+	}
+	err := vpc.Scale(nil, nil)
+	if err == nil || !strings.Contains(err.Error(), "cannot have children") {
+		t.Errorf("expected error for availability zone with children, got %v", err)
+	}
+}
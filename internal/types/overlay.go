@@ -0,0 +1,228 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package types
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"sort"
+
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	OVERLAY_PADDING = 15 // between the frame of an overlay and its members
+	OBSTACLE_GAP    = 15 // between a link segment moved out of an overlay and the frame
+)
+
+type Overlay struct {
+}
+
+func (o Overlay) Init() *Resource {
+	sr := new(Resource).Init()
+	sr.borderColor = &color.RGBA{125, 137, 152, 255}
+	sr.borderType = BORDER_TYPE_DASHED
+	sr.margin = &Margin{0, 0, 0, 0}
+	return sr
+}
+
+func (r *Resource) AddMember(member *Resource) error {
+	if member == nil {
+		return fmt.Errorf("unknown member resource - please see debug logs with -v flag")
+	}
+	r.members = append(r.members, member)
+	return nil
+}
+
+func (r *Resource) AddOverlay(overlay *Resource) error {
+	if overlay == nil {
+		return fmt.Errorf("unknown overlay resource - please see debug logs with -v flag")
+	}
+	if len(overlay.children) != 0 {
+		return fmt.Errorf("overlay %s cannot have children", overlay.label)
+	}
+	r.overlays = append(r.overlays, overlay)
+	return nil
+}
+
+func (r *Resource) GetOverlays() []*Resource {
+	return r.overlays
+}
+
+// ScaleOverlays fits every overlay around its members. It has to run after Scale and ZeroAdjust,
+// when the members have their final position. The resource grows when an overlay sticks out of it.
+func (r *Resource) ScaleOverlays() error {
+	if len(r.overlays) == 0 {
+		return nil
+	}
+	if err := r.fitOverlays(); err != nil {
+		return err
+	}
+	b := r.GetBindings()
+	grown := b
+	for _, overlay := range r.overlays {
+		o := overlay.GetBindings()
+		grown = grown.Union(image.Rect(
+			o.Min.X-r.padding.Left,
+			o.Min.Y-r.padding.Top,
+			o.Max.X+r.padding.Right,
+			o.Max.Y+r.padding.Bottom,
+		))
+	}
+	if grown == b {
+		return nil
+	}
+	log.Infof("Expand %s from %v to %v to fit overlays", r.label, b, grown)
+	r.SetBindings(grown)
+	if err := r.ZeroAdjust(); err != nil {
+		return fmt.Errorf("failed to adjust resource for overlays: %w", err)
+	}
+	return r.fitOverlays()
+}
+
+func (r *Resource) fitOverlays() error {
+	fitted := map[*Resource]bool{}
+	for _, overlay := range r.overlays {
+		if err := overlay.fitMembers(r, fitted, map[*Resource]bool{}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// fitMembers sets the bindings of the overlay to the union of its members, leaving room for
+// the padding and the header. Members that are overlays themselves are fitted first.
+func (r *Resource) fitMembers(parent *Resource, fitted, visited map[*Resource]bool) error {
+	if fitted[r] {
+		return nil
+	}
+	if visited[r] {
+		return fmt.Errorf("Cycle detected in overlay members at %s", r.label)
+	}
+	visited[r] = true
+	if len(r.members) == 0 {
+		return fmt.Errorf("overlay %s has no members", r.label)
+	}
+
+	fontFace, err := r.prepareFontFace(true, parent)
+	if err != nil {
+		return fmt.Errorf("failed to prepare font face: %w", err)
+	}
+	textWidth, textHeight := r.labelSize(fontFace)
+	if r.margin == nil {
+		r.margin = &Margin{0, 0, 0, 0}
+	}
+	if r.padding == nil {
+		r.padding = &Padding{OVERLAY_PADDING, OVERLAY_PADDING, OVERLAY_PADDING, OVERLAY_PADDING}
+	}
+	r.applySpacingOverrides()
+	if r.borderColor == nil {
+		r.borderColor = defaultResourceValues(true, false).borderColor
+	}
+
+	var b image.Rectangle
+	for i, member := range r.members {
+		if len(member.members) != 0 {
+			if err := member.fitMembers(parent, fitted, visited); err != nil {
+				return err
+			}
+		}
+		// Icons have bindings before they are placed, so the member must have been scaled
+		if len(member.members) == 0 && !member.scaled {
+			return fmt.Errorf("member of overlay %s is not placed in the diagram, add it to Children of a resource", r.label)
+		}
+		mb, err := member.visibleBounds()
+		if err != nil {
+			return err
+		}
+		if i == 0 {
+			b = mb
+		} else {
+			b = b.Union(mb)
+		}
+	}
+	b = image.Rect(
+		b.Min.X-r.padding.Left,
+		b.Min.Y-r.padding.Top-r.headerHeight(textHeight),
+		b.Max.X+r.padding.Right,
+		b.Max.Y+r.padding.Bottom,
+	)
+	if headerWidth := textWidth + r.iconBounds.Dx() + 30; headerWidth > b.Dx() {
+		b.Max.X = b.Min.X + headerWidth
+	}
+	r.SetBindings(b)
+	fitted[r] = true
+	return nil
+}
+
+// visibleBounds returns the bindings of a resource including the label drawn below its icon.
+func (r *Resource) visibleBounds() (image.Rectangle, error) {
+	b := r.GetBindings()
+	if len(r.children) != 0 || len(r.members) != 0 || r.label == "" {
+		return b, nil
+	}
+	fontFace, err := r.prepareFontFace(false, nil)
+	if err != nil {
+		return b, fmt.Errorf("failed to prepare font face: %w", err)
+	}
+	textWidth, textHeight := r.labelSize(fontFace)
+	b.Max.Y += textHeight
+	if textWidth > b.Dx() {
+		b.Min.X -= (textWidth - b.Dx()) / 2
+		b.Max.X = b.Min.X + textWidth
+	}
+	return b, nil
+}
+
+// avoidObstacles moves the inner segments of an orthogonal path out of the obstacles that
+// contain neither end of the link. The first and the last segments stay attached to the resources.
+func (l *Link) avoidObstacles(sourcePt, targetPt image.Point, controlPts []image.Point) []image.Point {
+	if len(l.obstacles) == 0 || len(controlPts) < 2 {
+		return controlPts
+	}
+	pts := append([]image.Point{sourcePt}, controlPts...)
+	pts = append(pts, targetPt)
+	keepsDirection := func(from, old, new int) bool {
+		return (old-from)*(new-from) > 0
+	}
+	for _, obstacle := range l.obstacles {
+		o := obstacle.GetBindings()
+		if sourcePt.In(o) || targetPt.In(o) {
+			continue
+		}
+		for i := 1; i+2 < len(pts); i++ {
+			p1, p2 := pts[i], pts[i+1]
+			if p1 == p2 {
+				continue
+			}
+			if p1.Y == p2.Y && p1.Y > o.Min.Y && p1.Y < o.Max.Y && minInt(p1.X, p2.X) < o.Max.X && maxInt(p1.X, p2.X) > o.Min.X {
+				candidates := []int{o.Min.Y - OBSTACLE_GAP, o.Max.Y + OBSTACLE_GAP}
+				sort.Slice(candidates, func(a, b int) bool {
+					return abs(candidates[a]-p1.Y) < abs(candidates[b]-p1.Y)
+				})
+				for _, y := range candidates {
+					if keepsDirection(pts[i-1].Y, p1.Y, y) && keepsDirection(pts[i+2].Y, p2.Y, y) {
+						log.Infof("Move link segment %v-%v out of overlay %s to y=%d", p1, p2, obstacle.label, y)
+						pts[i].Y, pts[i+1].Y = y, y
+						break
+					}
+				}
+			} else if p1.X == p2.X && p1.X > o.Min.X && p1.X < o.Max.X && minInt(p1.Y, p2.Y) < o.Max.Y && maxInt(p1.Y, p2.Y) > o.Min.Y {
+				candidates := []int{o.Min.X - OBSTACLE_GAP, o.Max.X + OBSTACLE_GAP}
+				sort.Slice(candidates, func(a, b int) bool {
+					return abs(candidates[a]-p1.X) < abs(candidates[b]-p1.X)
+				})
+				for _, x := range candidates {
+					if keepsDirection(pts[i-1].X, p1.X, x) && keepsDirection(pts[i+2].X, p2.X, x) {
+						log.Infof("Move link segment %v-%v out of overlay %s to x=%d", p1, p2, obstacle.label, x)
+						pts[i].X, pts[i+1].X = x, x
+						break
+					}
+				}
+			}
+		}
+	}
+	return pts[1 : len(pts)-1]
+}
@@ -0,0 +1,151 @@
+package types
+
+import (
+	"image"
+	"image/color"
+	"strings"
+	"testing"
+)
+
+func TestOverlayInit(t *testing.T) {
+	overlay := Overlay{}.Init()
+
+	if overlay.borderType != BORDER_TYPE_DASHED {
+		t.Errorf("Incorrect borderType: %v", overlay.borderType)
+	}
+
+	if *overlay.margin != (Margin{0, 0, 0, 0}) {
+		t.Errorf("Incorrect margin: %v", overlay.margin)
+	}
+
+	if overlay.padding != nil {
+		t.Errorf("Expected padding to be set when fitting members, got %v", overlay.padding)
+	}
+}
+
+func TestOverlayScale(t *testing.T) {
+	canvas := new(Resource).Init()
+	canvas.SetBindings(image.Rect(0, 0, 400, 300))
+	canvas.SetPadding(Padding{0, 0, 0, 0})
+	member1 := newGridCell(64, 64)
+	member1.Translation(50, 50)
+	member2 := newGridCell(64, 64)
+	member2.Translation(250, 150)
+	// The members are placed by hand instead of by the Scale of the canvas
+	member1.scaled = true
+	member2.scaled = true
+
+	overlay := Overlay{}.Init()
+	for _, m := range []*Resource{member1, member2} {
+		if err := overlay.AddMember(m); err != nil {
+			t.Fatalf("AddMember failed: %v", err)
+		}
+	}
+	if err := canvas.AddOverlay(overlay); err != nil {
+		t.Fatalf("AddOverlay failed: %v", err)
+	}
+	if err := canvas.ScaleOverlays(); err != nil {
+		t.Fatalf("ScaleOverlays failed: %v", err)
+	}
+
+	expected := image.Rect(50-OVERLAY_PADDING, 50-OVERLAY_PADDING, 314+OVERLAY_PADDING, 214+OVERLAY_PADDING)
+	if overlay.GetBindings() != expected {
+		t.Errorf("expected overlay bindings %v, got %v", expected, overlay.GetBindings())
+	}
+	if canvas.GetBindings() != image.Rect(0, 0, 400, 300) {
+		t.Errorf("expected canvas to keep its bindings, got %v", canvas.GetBindings())
+	}
+}
+
+func TestOverlayScaleGrowsCanvas(t *testing.T) {
+	canvas := new(Resource).Init()
+	canvas.SetBindings(image.Rect(0, 0, 100, 100))
+	canvas.SetPadding(Padding{0, 0, 0, 0})
+	member := newGridCell(64, 64)
+	canvas.AddChild(member)
+	member.scaled = true
+
+	overlay := Overlay{}.Init()
+	overlay.AddMember(member)
+	canvas.AddOverlay(overlay)
+	if err := canvas.ScaleOverlays(); err != nil {
+		t.Fatalf("ScaleOverlays failed: %v", err)
+	}
+
+	if canvas.GetBindings() != image.Rect(0, 0, 100+OVERLAY_PADDING, 100+OVERLAY_PADDING) {
+		t.Errorf("expected canvas to grow, got %v", canvas.GetBindings())
+	}
+	if member.GetBindings().Min != (image.Point{OVERLAY_PADDING, OVERLAY_PADDING}) {
+		t.Errorf("expected member to move with the canvas, got %v", member.GetBindings())
+	}
+	if overlay.GetBindings().Min != (image.Point{0, 0}) {
+		t.Errorf("expected overlay to be fitted again, got %v", overlay.GetBindings())
+	}
+}
+
+func TestOverlayUnplacedMember(t *testing.T) {
+	canvas := new(Resource).Init()
+	placed := newGridCell(64, 64)
+	canvas.AddChild(placed)
+	// An icon resource has bindings before it is placed, but this one is in no Children
+	unplaced := new(Resource).Init()
+	unplaced.iconBounds = image.Rect(0, 0, 64, 64)
+	unplaced.SetBindings(image.Rect(0, 0, 64, 64))
+
+	overlay := Overlay{}.Init()
+	overlay.AddMember(placed)
+	overlay.AddMember(unplaced)
+	canvas.AddOverlay(overlay)
+	if err := canvas.Scale(nil, nil); err != nil {
+		t.Fatalf("Scale failed: %v", err)
+	}
+	if err := canvas.ScaleOverlays(); err == nil || !strings.Contains(err.Error(), "not placed") {
+		t.Errorf("expected an error for the unplaced member, got %v", err)
+	}
+}
+
+func TestOverlayCycle(t *testing.T) {
+	canvas := new(Resource).Init()
+	canvas.SetBindings(image.Rect(0, 0, 100, 100))
+	canvas.SetPadding(Padding{0, 0, 0, 0})
+	overlay1 := Overlay{}.Init()
+	overlay2 := Overlay{}.Init()
+	overlay1.AddMember(overlay2)
+	overlay2.AddMember(overlay1)
+	canvas.AddOverlay(overlay1)
+	canvas.AddOverlay(overlay2)
+
+	if err := canvas.ScaleOverlays(); err == nil {
+		t.Error("expected error for cyclic overlay members")
+	}
+}
+
+func TestAvoidObstacles(t *testing.T) {
+	obstacle := Overlay{}.Init()
+	obstacle.SetBindings(image.Rect(110, 100, 200, 200))
+
+	link := Link{}.Init(new(Resource).Init(), WINDROSE_E, ArrowHead{}, new(Resource).Init(), WINDROSE_W, ArrowHead{}, 2, color.RGBA{0, 0, 0, 255})
+	link.SetObstacles([]*Resource{obstacle})
+
+	// E to W link whose vertical segment runs through the obstacle
+	sourcePt := image.Point{0, 120}
+	targetPt := image.Point{300, 180}
+	controlPts := []image.Point{{150, 120}, {150, 180}}
+	result := link.avoidObstacles(sourcePt, targetPt, controlPts)
+
+	expected := []image.Point{{110 - OBSTACLE_GAP, 120}, {110 - OBSTACLE_GAP, 180}}
+	if len(result) != len(expected) {
+		t.Fatalf("expected %v, got %v", expected, result)
+	}
+	for i := range expected {
+		if result[i] != expected[i] {
+			t.Errorf("point %d: expected %v, got %v", i, expected[i], result[i])
+		}
+	}
+
+	// Obstacles around an end of the link are ignored
+	result = link.avoidObstacles(image.Point{150, 150}, targetPt, []image.Point{{250, 150}, {250, 180}})
+	if result[0] != (image.Point{250, 150}) || result[1] != (image.Point{250, 180}) {
+		t.Errorf("expected path to be unchanged, got %v", result)
+	}
+}
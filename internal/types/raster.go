@@ -0,0 +1,238 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package types
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"math"
+
+	"github.com/awslabs/diagram-as-code/internal/vector"
+	raster "golang.org/x/image/vector"
+)
+
+const (
+	JOIN_MITER = "miter"
+	JOIN_ROUND = "round"
+
+	MITER_LIMIT = 4 // longest miter relative to the width, sharper bends are beveled
+)
+
+// polygon is a closed outline. Points at integer coordinates are the centers of pixels, as for the
+// points of the links.
+type polygon []vector.Vector
+
+// area returns the signed area of the polygon, positive when it runs clockwise on the canvas
+func (p polygon) area() float64 {
+	a := 0.0
+	for i := range p {
+		j := (i + 1) % len(p)
+		a += p[i].X*p[j].Y - p[j].X*p[i].Y
+	}
+	return a / 2
+}
+
+// clockwise returns the polygon running clockwise, so that overlapping polygons add up
+func (p polygon) clockwise() polygon {
+	if p.area() >= 0 {
+		return p
+	}
+	r := make(polygon, len(p))
+	for i := range p {
+		r[i] = p[len(p)-1-i]
+	}
+	return r
+}
+
+// fillPolygons fills the polygons with anti-aliasing. Overlapping polygons running the same way
+// merge, and a polygon running the other way cuts a hole. The color is not premultiplied, as
+// the colors of DAC files.
+func fillPolygons(img *image.RGBA, polygons []polygon, c color.RGBA) {
+	fillPolygonsWith(img, polygons, image.NewUniform(color.NRGBA(c)))
+}
+
+// fillPolygonsWith fills the polygons with the colors of src at the same points of the canvas
+func fillPolygonsWith(img *image.RGBA, polygons []polygon, src image.Image) {
+	// Only the bounding box is rasterized, which keeps small shapes on large canvases fast
+	bounds := polygonBounds(polygons).Intersect(img.Bounds())
+	if bounds.Empty() {
+		return
+	}
+	rasterize(bounds, polygons).Draw(img, bounds, src, bounds.Min)
+}
+
+// polygonBounds returns the pixels the polygons may cover
+func polygonBounds(polygons []polygon) image.Rectangle {
+	minX, minY := math.Inf(1), math.Inf(1)
+	maxX, maxY := math.Inf(-1), math.Inf(-1)
+	for _, p := range polygons {
+		for _, pt := range p {
+			minX, minY = math.Min(minX, pt.X), math.Min(minY, pt.Y)
+			maxX, maxY = math.Max(maxX, pt.X), math.Max(maxY, pt.Y)
+		}
+	}
+	if math.IsInf(minX, 0) {
+		return image.Rectangle{}
+	}
+	return image.Rect(int(math.Floor(minX)), int(math.Floor(minY)), int(math.Ceil(maxX))+2, int(math.Ceil(maxY))+2)
+}
+
+// rasterize returns the coverage of the polygons over the pixels of bounds
+func rasterize(bounds image.Rectangle, polygons []polygon) *raster.Rasterizer {
+	z := raster.NewRasterizer(bounds.Dx(), bounds.Dy())
+	origin := vector.New(float64(bounds.Min.X)-0.5, float64(bounds.Min.Y)-0.5)
+	for _, p := range polygons {
+		if len(p) < 3 {
+			continue
+		}
+		for i, pt := range p {
+			pt = pt.Sub(origin)
+			if i == 0 {
+				z.MoveTo(float32(pt.X), float32(pt.Y))
+			} else {
+				z.LineTo(float32(pt.X), float32(pt.Y))
+			}
+		}
+		z.ClosePath()
+	}
+	return z
+}
+
+// fillRect fills whole pixels, for the frames and the backgrounds of resources
+func fillRect(img *image.RGBA, r image.Rectangle, c color.RGBA) {
+	draw.Draw(img, r, image.NewUniform(color.NRGBA(c)), image.Point{}, draw.Over)
+}
+
+// strokePolyline returns the outline of a line of the given width through the points, with butt
+// caps and joins of the given style
+func strokePolyline(pts []vector.Vector, width float64, join string, closed bool) []polygon {
+	pts = dedupPoints(pts, closed)
+	half := width / 2
+	polygons := []polygon{}
+	if len(pts) < 2 || width <= 0 {
+		return polygons
+	}
+	n := len(pts) - 1
+	if closed {
+		n = len(pts)
+	}
+	for i := 0; i < n; i++ {
+		p1, p2 := pts[i], pts[(i+1)%len(pts)]
+		normal := p2.Sub(p1).Normalize().Perpendicular().Scale(half)
+		polygons = append(polygons, polygon{p1.Add(normal), p2.Add(normal), p2.Sub(normal), p1.Sub(normal)}.clockwise())
+	}
+	for i := 0; i < len(pts); i++ {
+		if !closed && (i == 0 || i == len(pts)-1) {
+			continue
+		}
+		prev := pts[(i+len(pts)-1)%len(pts)]
+		next := pts[(i+1)%len(pts)]
+		polygons = append(polygons, joinPolygon(prev, pts[i], next, half, join)...)
+	}
+	return polygons
+}
+
+// joinPolygon fills the wedge on the outer side of the bend at pt
+func joinPolygon(prev, pt, next vector.Vector, half float64, join string) []polygon {
+	d1 := pt.Sub(prev).Normalize()
+	d2 := next.Sub(pt).Normalize()
+	cross := d1.X*d2.Y - d1.Y*d2.X
+	if math.Abs(cross) < 1e-9 && d1.Dot(d2) > 0 {
+		// No bend
+		return nil
+	}
+	if join == JOIN_ROUND {
+		return []polygon{circlePolygon(pt, half)}
+	}
+	// The outer side is on the left of a right turn on the canvas
+	side := 1.0
+	if cross > 0 {
+		side = -1
+	}
+	n1 := d1.Perpendicular().Scale(half * side)
+	n2 := d2.Perpendicular().Scale(half * side)
+	bevel := polygon{pt, pt.Add(n1), pt.Add(n2)}.clockwise()
+	// The miter tip is on the bisector of the two offset edges
+	bisector := n1.Add(n2)
+	cos := bisector.Length() / (2 * half)
+	if cos < 1e-9 || 1/cos > MITER_LIMIT {
+		return []polygon{bevel}
+	}
+	tip := pt.Add(bisector.Normalize().Scale(half / cos))
+	return []polygon{polygon{pt, pt.Add(n1), tip, pt.Add(n2)}.clockwise()}
+}
+
+// circlePolygon returns a circle as a polygon with segments about a pixel long
+func circlePolygon(center vector.Vector, radius float64) polygon {
+	steps := maxInt(8, int(math.Ceil(2*math.Pi*radius)))
+	p := make(polygon, steps)
+	for s := 0; s < steps; s++ {
+		angle := 2 * math.Pi * float64(s) / float64(steps)
+		p[s] = center.Add(vector.New(math.Cos(angle), math.Sin(angle)).Scale(radius))
+	}
+	return p.clockwise()
+}
+
+// ringPolygons returns a circle stroked with the given width
+func ringPolygons(center vector.Vector, radius, width float64) []polygon {
+	outer := circlePolygon(center, radius+width/2)
+	inner := circlePolygon(center, math.Max(0, radius-width/2))
+	// The inner circle runs the other way to cut the hole
+	for i, j := 0, len(inner)-1; i < j; i, j = i+1, j-1 {
+		inner[i], inner[j] = inner[j], inner[i]
+	}
+	return []polygon{outer, inner}
+}
+
+func polylineLength(pts []vector.Vector) float64 {
+	length := 0.0
+	for i := 0; i+1 < len(pts); i++ {
+		length += pts[i+1].Sub(pts[i]).Length()
+	}
+	return length
+}
+
+// subPolyline returns the part of the polyline from distance a to distance b along it
+func subPolyline(pts []vector.Vector, a, b float64) []vector.Vector {
+	part := []vector.Vector{}
+	travelled := 0.0
+	for i := 0; i+1 < len(pts); i++ {
+		segment := pts[i+1].Sub(pts[i])
+		length := segment.Length()
+		if length == 0 {
+			continue
+		}
+		start, end := travelled, travelled+length
+		travelled = end
+		if end < a || start > b {
+			continue
+		}
+		dir := segment.Scale(1 / length)
+		if len(part) == 0 {
+			part = append(part, pts[i].Add(dir.Scale(math.Max(a-start, 0))))
+		}
+		if end <= b {
+			part = append(part, pts[i+1])
+		} else {
+			part = append(part, pts[i].Add(dir.Scale(b-start)))
+			break
+		}
+	}
+	return part
+}
+
+// dedupPoints drops the points equal to the previous one
+func dedupPoints(pts []vector.Vector, closed bool) []vector.Vector {
+	r := []vector.Vector{}
+	for _, pt := range pts {
+		if len(r) == 0 || pt.Sub(r[len(r)-1]).Length() > 1e-6 {
+			r = append(r, pt)
+		}
+	}
+	if closed && len(r) > 1 && r[0].Sub(r[len(r)-1]).Length() <= 1e-6 {
+		r = r[:len(r)-1]
+	}
+	return r
+}
@@ -0,0 +1,50 @@
+package types
+
+import (
+	"image"
+	"image/color"
+	"reflect"
+	"testing"
+
+	"github.com/awslabs/diagram-as-code/internal/vector"
+)
+
+func TestSubPolyline(t *testing.T) {
+	pts := []vector.Vector{vector.New(0, 0), vector.New(10, 0), vector.New(10, 10)}
+	expected := []vector.Vector{vector.New(5, 0), vector.New(10, 0), vector.New(10, 3)}
+	if part := subPolyline(pts, 5, 13); !reflect.DeepEqual(part, expected) {
+		t.Errorf("expected %v, got %v", expected, part)
+	}
+	if length := polylineLength(pts); length != 20 {
+		t.Errorf("expected a length of 20, got %v", length)
+	}
+}
+
+func TestStrokePolyline(t *testing.T) {
+	black := color.RGBA{0, 0, 0, 255}
+	img := image.NewRGBA(image.Rect(0, 0, 20, 20))
+	pts := []vector.Vector{vector.New(2, 4), vector.New(15, 4), vector.New(15, 18)}
+	fillPolygons(img, strokePolyline(pts, 3, JOIN_MITER, false), black)
+	// The miter fills the outer corner, and overlapping polygons don't add up
+	for _, p := range []image.Point{{8, 4}, {8, 3}, {15, 10}, {16, 3}, {15, 4}} {
+		if c := img.RGBAAt(p.X, p.Y); c != black {
+			t.Errorf("expected (%d, %d) to be black, got %v", p.X, p.Y, c)
+		}
+	}
+	for _, p := range []image.Point{{8, 6}, {13, 10}, {0, 4}} {
+		if c := img.RGBAAt(p.X, p.Y); c.A != 0 {
+			t.Errorf("expected (%d, %d) to stay empty, got %v", p.X, p.Y, c)
+		}
+	}
+}
+
+func TestRingPolygons(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 21, 21))
+	fillPolygons(img, ringPolygons(vector.New(10, 10), 6, 2), color.RGBA{0, 0, 0, 255})
+	if img.RGBAAt(10, 10).A != 0 {
+		t.Error("expected a hole in the middle of the ring")
+	}
+	if img.RGBAAt(16, 10).A != 255 || img.RGBAAt(10, 4).A != 255 {
+		t.Error("expected the ring around the hole")
+	}
+}
@@ -32,6 +32,8 @@ type BORDER_TYPE int
 const (
 	BORDER_TYPE_STRAIGHT BORDER_TYPE = iota
 	BORDER_TYPE_DASHED
+	BORDER_TYPE_DOTTED
+	BORDER_TYPE_DASH_DOT
 )
 
 type ICON_FILL_TYPE int
@@ -47,21 +49,53 @@ type Resource struct {
 	iconBounds     image.Rectangle
 	borderColor    *color.RGBA
 	borderType     BORDER_TYPE
+	borderWidth    int
+	borderDashes   []float64
 	fillColor      color.RGBA
+	gradient       *Gradient // fills the background instead of fillColor
+	cornerRadius   int
+	shadow         *Shadow
 	label          string
 	labelFont      string
 	labelColor     *color.RGBA
 	headerAlign    string // left(default) / center / right
 	margin         *Margin
 	padding        *Padding
+	marginSpacing  *Spacing
+	paddingSpacing *Spacing
+	width          int // fixed width, 0 to fit the content
+	height         int // fixed height, 0 to fit the content
+	minWidth       int
+	divider        bool
+	offset         image.Point  // moved by after the layout
+	pin            *image.Point // position from the top-left corner of the content of the parent, nil to follow the layout
+	pinOrigin      image.Point  // top-left corner of the content, where the pins of the children start, from the frame
 	direction      string
+	reverse        bool // Flag: if true, children are placed from right to left or from bottom to top
 	align          string
+	gridColumns    int
+	gridRows       int
+	columnSpan     int
+	rowSpan        int
+	rowAlign       string // top / center(default) / bottom
+	columnAlign    string // left / center(default) / right
+	matrixColumn   *Resource
+	tier           string
+	tiers          []string
+	members        []*Resource
+	overlays       []*Resource
 	links          []*Link
 	children       []*Resource
+	drawOrder      []*Resource // children in the order they are drawn, nil for the order of children
 	borderChildren []*BorderChild
+	ports          map[string]Anchor
 	iconfill       ResourceIconFill
 	drawn          bool
+	scaled         bool // Flag: if true, Scale has placed the resource in the diagram
 	groupingOffset bool // Flag: if true, enable grouping offset for links
+	equalSize      bool // Flag: if true, child groups share the size of the largest one
+	wrap           int  // number of children on a row or a column of a stack, 0 for no wrapping
+	maxWidth       int  // width of the rows of a horizontal stack, 0 for no wrapping
 }
 
 type ResourceIconFill struct {
@@ -121,6 +155,7 @@ func (r *Resource) Init() *Resource {
 	}
 	rr.borderColor = nil
 	rr.borderType = BORDER_TYPE_STRAIGHT
+	rr.borderWidth = WIDTH
 	rr.fillColor = color.RGBA{0, 0, 0, 0}
 	rr.label = ""
 	rr.labelFont = ""
@@ -130,6 +165,10 @@ func (r *Resource) Init() *Resource {
 	rr.padding = nil
 	rr.direction = "horizontal"
 	rr.align = "center"
+	rr.columnSpan = 1
+	rr.rowSpan = 1
+	rr.rowAlign = "center"
+	rr.columnAlign = "center"
 	rr.drawn = false
 	return &rr
 }
@@ -163,6 +202,10 @@ func (r *Resource) SetIconBounds(bounds image.Rectangle) {
 	r.iconBounds = bounds
 }
 
+func (r *Resource) GetIconBounds() image.Rectangle {
+	return r.iconBounds
+}
+
 func (r *Resource) SetBindings(bindings image.Rectangle) {
 	r.bindings = &bindings
 }
@@ -187,6 +230,23 @@ func (r *Resource) SetPadding(padding Padding) {
 	r.padding = &padding
 }
 
+func (r *Resource) SetMarginOverride(margin Spacing) {
+	r.marginSpacing = &margin
+}
+
+func (r *Resource) SetPaddingOverride(padding Spacing) {
+	r.paddingSpacing = &padding
+}
+
+func (r *Resource) SetSize(width, height int) {
+	r.width = width
+	r.height = height
+}
+
+func (r *Resource) SetMinWidth(minWidth int) {
+	r.minWidth = minWidth
+}
+
 func (r *Resource) SetBorderColor(borderColor color.RGBA) {
 	r.borderColor = &borderColor
 }
@@ -197,6 +257,7 @@ func (r *Resource) SetBorderType(borderType BORDER_TYPE) {
 
 func (r *Resource) SetFillColor(fillColor color.RGBA) {
 	r.fillColor = fillColor
+	r.gradient = nil
 }
 
 func (r *Resource) SetLabel(label *string, labelColor *color.RGBA, labelFont *string) {
@@ -216,7 +277,97 @@ func (r *Resource) SetAlign(align string) {
 }
 
 func (r *Resource) SetDirection(direction string) {
-	r.direction = direction
+	switch direction {
+	case "horizontal-reverse", "vertical-reverse":
+		r.direction = strings.TrimSuffix(direction, "-reverse")
+		r.reverse = true
+	default:
+		r.direction = direction
+		r.reverse = false
+	}
+}
+
+// orderedChildren returns the children in the order they are placed in a stack
+func (r *Resource) orderedChildren() []*Resource {
+	children := r.layoutChildren()
+	if !r.reverse {
+		return children
+	}
+	ordered := make([]*Resource, len(children))
+	for i, child := range children {
+		ordered[len(children)-1-i] = child
+	}
+	return ordered
+}
+
+// layoutChildren returns the children placed by the layout of the resource, without the pinned ones
+func (r *Resource) layoutChildren() []*Resource {
+	children := []*Resource{}
+	for _, child := range r.children {
+		if child.pin == nil {
+			children = append(children, child)
+		}
+	}
+	return children
+}
+
+// drawnChildren returns the children in the order they are drawn
+func (r *Resource) drawnChildren() []*Resource {
+	if r.drawOrder != nil {
+		return r.drawOrder
+	}
+	return r.children
+}
+
+func (r *Resource) pinnedChildren() []*Resource {
+	children := []*Resource{}
+	for _, child := range r.children {
+		if child.pin != nil {
+			children = append(children, child)
+		}
+	}
+	return children
+}
+
+func (r *Resource) SetOffset(offset image.Point) {
+	r.offset = offset
+}
+
+// GetPinOrigin returns the point the pins of the children are measured from, once the resource is scaled
+func (r *Resource) GetPinOrigin() image.Point {
+	return r.GetBindings().Min.Add(r.pinOrigin)
+}
+
+func (r *Resource) SetPin(pin image.Point) {
+	r.pin = &pin
+}
+
+func (r *Resource) SetGrid(columns, rows int) {
+	r.gridColumns = columns
+	r.gridRows = rows
+}
+
+func (r *Resource) SetGridSpan(columnSpan, rowSpan int) {
+	r.columnSpan = columnSpan
+	r.rowSpan = rowSpan
+}
+
+func (r *Resource) SetGridAlign(rowAlign, columnAlign string) {
+	if rowAlign != "" {
+		r.rowAlign = rowAlign
+	}
+	if columnAlign != "" {
+		r.columnAlign = columnAlign
+	}
+}
+
+func (r *Resource) SetMatrixCell(availabilityZone *Resource, tier string) {
+	r.matrixColumn = availabilityZone
+	r.tier = tier
+}
+
+func (r *Resource) SetTiers(tiers []string) {
+	r.tiers = tiers
 }
 
 func (r *Resource) SetIconFill(t ICON_FILL_TYPE, color *color.RGBA) {
@@ -230,6 +381,15 @@ func (r *Resource) SetGroupingOffset(enable bool) {
 	r.groupingOffset = enable
 }
 
+func (r *Resource) SetWrap(wrap, maxWidth int) {
+	r.wrap = wrap
+	r.maxWidth = maxWidth
+}
+
+func (r *Resource) SetEqualSize(enable bool) {
+	r.equalSize = enable
+}
+
 func (r *Resource) AddLink(link *Link) {
 	r.links = append(r.links, link)
 }
@@ -238,6 +398,10 @@ func (r *Resource) GetLinks() []*Link {
 	return r.links
 }
 
+func (r *Resource) GetChildren() []*Resource {
+	return r.children
+}
+
 func (r *Resource) AddParent() {
 }
 
@@ -324,6 +488,22 @@ func (r *Resource) prepareFontFace(hasChild bool, parent *Resource) (font.Face,
 	return truetype.NewFace(ft, &opt), nil
 }
 
+// labelSize returns the size of the label box including its spacing
+func (r *Resource) labelSize(fontFace font.Face) (int, int) {
+	textWidth := 0
+	textHeight := 0
+	if r.label != "" {
+		textHeight = 10
+		texts := strings.Split(r.label, "\n")
+		for _, line := range texts {
+			textBindings, _ := font.BoundString(fontFace, line)
+			textWidth = max(textWidth, textBindings.Max.X.Floor()-textBindings.Min.X.Ceil()+20)
+			textHeight += textBindings.Max.Y.Floor() - textBindings.Min.Y.Ceil() + 10
+		}
+	}
+	return textWidth, textHeight
+}
+
 func (r *Resource) Scale(parent *Resource, visited map[*Resource]bool) error {
 	log.Infof("Scale %s", r.label)
 
@@ -336,6 +516,7 @@ func (r *Resource) Scale(parent *Resource, visited map[*Resource]bool) error {
 		return fmt.Errorf("Cycle detected in resource tree at %s", r.label)
 	}
 	visited[r] = true
+	r.scaled = true
 
 	var prev *Resource
 	b := image.Rectangle{
@@ -352,24 +533,34 @@ func (r *Resource) Scale(parent *Resource, visited map[*Resource]bool) error {
 	hasBorderChildren := len(r.borderChildren) != 0
 	hasIcon := r.iconImage.Bounds().Max.X != 0
 	log.Infof("hasIcon: %t\n", hasIcon)
-	textWidth := 0
-	textHeight := 0
 	fontFace, err := r.prepareFontFace(hasChildren, parent)
 	if err != nil {
 		return fmt.Errorf("failed to prepare font face: %w", err)
 	}
-	if r.label != "" {
-		textHeight = 10
-		texts := strings.Split(r.label, "\n")
-		for _, line := range texts {
-			textBindings, _ := font.BoundString(fontFace, line)
-			textWidth = max(textWidth, textBindings.Max.X.Floor()-textBindings.Min.X.Ceil()+20)
-			textHeight += textBindings.Max.Y.Floor() - textBindings.Min.Y.Ceil() + 10
-		}
+	textWidth, textHeight := r.labelSize(fontFace)
+	if r.divider {
+		r.scaleDivider(parent, textWidth, textHeight)
 	}
 	if r.bindings == nil {
 		r.bindings = defaultResourceValues(hasChildren, hasIcon).bindings
 	}
+	iconWidth := r.iconBounds.Dx() // width under which the label is centered
+	if !hasChildren && (r.width > 0 || r.height > 0 || r.minWidth > 0) {
+		// The icon keeps its aspect ratio, centered in the given size
+		size := r.bindings.Size()
+		if r.width > 0 {
+			size.X = r.width
+		}
+		if r.height > 0 {
+			size.Y = r.height
+		}
+		size.X = maxInt(size.X, r.minWidth)
+		r.bindings = &image.Rectangle{r.bindings.Min, r.bindings.Min.Add(size)}
+		if hasIcon {
+			r.iconBounds = fitIcon(r.iconBounds.Size(), size)
+			iconWidth = size.X
+		}
+	}
 	if r.margin == nil {
 		r.margin = defaultResourceValues(hasChildren, hasIcon).margin
 		// Expand bindings to fit text size
@@ -377,7 +568,7 @@ func (r *Resource) Scale(parent *Resource, visited map[*Resource]bool) error {
 			// Resource (no child)
 			log.Infof("textHeight: %d\n", textHeight)
 			r.margin.Bottom += textHeight
-			_m := (textWidth - r.iconBounds.Dx()) / 2
+			_m := (textWidth - iconWidth) / 2
 			r.margin.Left = maxInt(r.margin.Left, _m)
 			r.margin.Right = maxInt(r.margin.Right, _m)
 		}
@@ -425,6 +616,7 @@ func (r *Resource) Scale(parent *Resource, visited map[*Resource]bool) error {
 			r.padding.Left += addPadding.Left
 		}
 	}
+	r.applySpacingOverrides()
 	if r.borderColor == nil {
 		r.borderColor = defaultResourceValues(hasChildren, hasIcon).borderColor
 	}
@@ -448,84 +640,130 @@ func (r *Resource) Scale(parent *Resource, visited map[*Resource]bool) error {
 		b = *prev.bindings
 	}
 
-	for _, subResource := range r.children {
-		err := subResource.Scale(r, visited)
+	if r.direction == "grid" || r.direction == "az-matrix" || r.direction == "auto-graph" || r.isFlow() {
+		// These layouts need the size of every child before placing any of them
+		for _, subResource := range r.children {
+			err := subResource.Scale(r, visited)
+			if err != nil {
+				return err
+			}
+		}
+		// Flows stretch their children line by line
+		if r.equalSize {
+			if err := r.stretchChildren(); err != nil {
+				return err
+			}
+		}
+		switch r.direction {
+		case "grid":
+			err = r.layoutGrid()
+		case "az-matrix":
+			err = r.layoutMatrix()
+		case "auto-graph":
+			err = r.layoutLayered()
+		case "horizontal", "vertical":
+			err = r.layoutFlow()
+		}
 		if err != nil {
 			return err
 		}
-
-		bindings := subResource.GetBindings()
-		margin := subResource.GetMargin()
-		if prev != nil {
-			prevBindings := prev.GetBindings()
-			prevMargin := prev.GetMargin()
-			if r.direction == "horizontal" {
-				switch r.align {
-				case "top":
-					if err := subResource.Translation(
-						prevBindings.Max.X+prevMargin.Right+margin.Left-bindings.Min.X,
-						prevBindings.Min.Y-prevMargin.Top+margin.Top-bindings.Min.Y,
-					); err != nil {
-						return fmt.Errorf("failed to translate subresource: %w", err)
-					}
-				case "center":
-					if err := subResource.Translation(
-						prevBindings.Max.X+prevMargin.Right+margin.Left-bindings.Min.X,
-						prevBindings.Min.Y+(prevBindings.Dy()-bindings.Dy())/2-bindings.Min.Y,
-					); err != nil {
-						return fmt.Errorf("failed to translate subresource: %w", err)
-					}
-				case "bottom":
-					if err := subResource.Translation(
-						prevBindings.Max.X+prevMargin.Right+margin.Left-bindings.Min.X,
-						prevBindings.Max.Y+prevMargin.Bottom-margin.Bottom-bindings.Max.Y,
-					); err != nil {
-						return fmt.Errorf("failed to translate subresource: %w", err)
-					}
-				default:
-					return fmt.Errorf("unknown align %s in the direction(%s) on %s", r.align, r.direction, r.label)
+	} else {
+		stretch := r.align == "stretch" || r.equalSize
+		if stretch {
+			// Stretched children need the size of every sibling before placing any of them
+			for _, subResource := range r.children {
+				err := subResource.Scale(r, visited)
+				if err != nil {
+					return err
 				}
-			} else {
-				switch r.align {
-				case "left":
-					if err := subResource.Translation(
-						prevBindings.Min.X-prevMargin.Left+margin.Left-bindings.Min.X,
-						prevBindings.Max.Y+prevMargin.Bottom+margin.Top-bindings.Min.Y,
-					); err != nil {
-						return fmt.Errorf("failed to translate subresource: %w", err)
-					}
-				case "center":
-					if err := subResource.Translation(
-						prevBindings.Min.X+(prevBindings.Dx()-bindings.Dx())/2-bindings.Min.X,
-						prevBindings.Max.Y+prevMargin.Bottom+margin.Top-bindings.Min.Y,
-					); err != nil {
-						return fmt.Errorf("failed to translate subresource: %w", err)
+			}
+			if err := r.stretchChildren(); err != nil {
+				return err
+			}
+		}
+		for _, subResource := range r.orderedChildren() {
+			if !stretch {
+				err := subResource.Scale(r, visited)
+				if err != nil {
+					return err
+				}
+			}
+
+			bindings := subResource.GetBindings()
+			margin := subResource.GetMargin()
+			if prev != nil {
+				prevBindings := prev.GetBindings()
+				prevMargin := prev.GetMargin()
+				if r.direction == "horizontal" {
+					switch r.align {
+					case "top":
+						if err := subResource.Translation(
+							prevBindings.Max.X+prevMargin.Right+margin.Left-bindings.Min.X,
+							prevBindings.Min.Y-prevMargin.Top+margin.Top-bindings.Min.Y,
+						); err != nil {
+							return fmt.Errorf("failed to translate subresource: %w", err)
+						}
+					case "center", "stretch":
+						if err := subResource.Translation(
+							prevBindings.Max.X+prevMargin.Right+margin.Left-bindings.Min.X,
+							prevBindings.Min.Y+(prevBindings.Dy()-bindings.Dy())/2-bindings.Min.Y,
+						); err != nil {
+							return fmt.Errorf("failed to translate subresource: %w", err)
+						}
+					case "bottom":
+						if err := subResource.Translation(
+							prevBindings.Max.X+prevMargin.Right+margin.Left-bindings.Min.X,
+							prevBindings.Max.Y+prevMargin.Bottom-margin.Bottom-bindings.Max.Y,
+						); err != nil {
+							return fmt.Errorf("failed to translate subresource: %w", err)
+						}
+					default:
+						return fmt.Errorf("unknown align %s in the direction(%s) on %s", r.align, r.direction, r.label)
 					}
-				case "right":
-					if err := subResource.Translation(
-						prevBindings.Max.X+prevMargin.Right-margin.Right-bindings.Min.X,
-						prevBindings.Max.Y+prevMargin.Bottom+margin.Top-bindings.Min.Y,
-					); err != nil {
-						return fmt.Errorf("failed to translate subresource: %w", err)
+				} else {
+					switch r.align {
+					case "left":
+						if err := subResource.Translation(
+							prevBindings.Min.X-prevMargin.Left+margin.Left-bindings.Min.X,
+							prevBindings.Max.Y+prevMargin.Bottom+margin.Top-bindings.Min.Y,
+						); err != nil {
+							return fmt.Errorf("failed to translate subresource: %w", err)
+						}
+					case "center", "stretch":
+						if err := subResource.Translation(
+							prevBindings.Min.X+(prevBindings.Dx()-bindings.Dx())/2-bindings.Min.X,
+							prevBindings.Max.Y+prevMargin.Bottom+margin.Top-bindings.Min.Y,
+						); err != nil {
+							return fmt.Errorf("failed to translate subresource: %w", err)
+						}
+					case "right":
+						if err := subResource.Translation(
+							prevBindings.Max.X+prevMargin.Right-margin.Right-bindings.Min.X,
+							prevBindings.Max.Y+prevMargin.Bottom+margin.Top-bindings.Min.Y,
+						); err != nil {
+							return fmt.Errorf("failed to translate subresource: %w", err)
+						}
+					default:
+						return fmt.Errorf("unknown align %s in the direction(%s) on %s", r.align, r.direction, r.label)
 					}
-				default:
-					return fmt.Errorf("unknown align %s in the direction(%s) on %s", r.align, r.direction, r.label)
 				}
 			}
+			prev = subResource
 		}
-		bindings = subResource.GetBindings()
-		b.Min.X = minInt(b.Min.X, bindings.Min.X-margin.Left-r.padding.Left)
-		headerHeight := maxInt(r.iconBounds.Dy(), textHeight)
-		if r.headerAlign == "center" {
-			headerHeight = r.iconBounds.Dy() + textHeight
+	}
+	for _, subResource := range r.layoutChildren() {
+		if err := subResource.applyOffset(); err != nil {
+			return err
 		}
-		b.Min.Y = minInt(b.Min.Y, bindings.Min.Y-margin.Top-headerHeight-r.padding.Top)
-		b.Max.X = maxInt(b.Max.X, bindings.Max.X+margin.Right+r.padding.Right)
-		b.Max.Y = maxInt(b.Max.Y, bindings.Max.Y+margin.Bottom+r.padding.Bottom)
-		prev = subResource
+		r.expandBindings(&b, subResource, textHeight)
+	}
+	pinned := r.pinnedChildren()
+	if len(pinned) != 0 && b.Min.X == math.MaxInt {
+		// Only pinned children, the frame starts from the header
+		b = image.Rectangle{}
 	}
 	// Expand bindings to fit text size
-	if hasChildren && r.direction == "horizontal" {
+	if hasChildren && (r.direction == "horizontal" || r.direction == "grid" || r.direction == "az-matrix" || r.direction == "auto-graph") {
 		// Group (has child)
 		if textWidth+r.iconBounds.Dx()+30 > b.Dx() {
 			_dx := b.Dx()
@@ -533,29 +771,188 @@ func (r *Resource) Scale(parent *Resource, visited map[*Resource]bool) error {
 			b.Max.X += (textWidth + r.iconBounds.Dx() + 30 - _dx) / 2
 		}
 	}
+	if hasChildren && b.Min.X != math.MaxInt {
+		r.applySize(&b)
+		r.spanDividers(b, textHeight)
+	}
+	if err := r.placePinnedChildren(&b, pinned, textHeight, visited); err != nil {
+		return err
+	}
 	if b.Min.X != math.MaxInt {
 		r.SetBindings(b)
 	}
 	for _, borderChild := range r.borderChildren {
-		pt, err := calcPosition(r.GetBindings(), borderChild.Position)
-		if err != nil {
-			return fmt.Errorf("failed to calculate position for border child: %w", err)
-		}
 		err = borderChild.Resource.Scale(r, visited) // to initialize default values
 		if err != nil {
 			return err
 		}
-		bindings := borderChild.Resource.GetBindings()
-		if err := borderChild.Resource.Translation(
-			pt.X-(bindings.Min.X+bindings.Max.X)/2,
-			pt.Y-(bindings.Min.Y+bindings.Max.Y)/2,
+		if err := r.placeBorderChild(borderChild); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// fitIcon returns the bounds of an icon of the given size centered in a box, scaled down with its
+// aspect ratio when the box is smaller than the icon
+func fitIcon(icon, box image.Point) image.Rectangle {
+	if icon.X > box.X || icon.Y > box.Y {
+		if icon.X*box.Y > icon.Y*box.X {
+			icon = image.Point{box.X, icon.Y * box.X / icon.X}
+		} else {
+			icon = image.Point{icon.X * box.Y / icon.Y, box.Y}
+		}
+	}
+	min := image.Point{(box.X - icon.X) / 2, (box.Y - icon.Y) / 2}
+	return image.Rectangle{min, min.Add(icon)}
+}
+
+// placeBorderChild centers the border child on its position of the frame.
+func (r *Resource) placeBorderChild(borderChild *BorderChild) error {
+	pt, err := calcPosition(r.GetBindings(), borderChild.Position)
+	if err != nil {
+		return fmt.Errorf("failed to calculate position for border child: %w", err)
+	}
+	bindings := borderChild.Resource.GetBindings()
+	if err := borderChild.Resource.Translation(
+		pt.X-(bindings.Min.X+bindings.Max.X)/2,
+		pt.Y-(bindings.Min.Y+bindings.Max.Y)/2,
+	); err != nil {
+		return fmt.Errorf("failed to translate border child resource: %w", err)
+	}
+	return borderChild.Resource.applyOffset()
+}
+
+// applyOffset moves the resource by its manual Offset once the layout has placed it
+func (r *Resource) applyOffset() error {
+	if r.offset == (image.Point{}) {
+		return nil
+	}
+	log.Infof("Offset %s by %v", r.label, r.offset)
+	if err := r.Translation(r.offset.X, r.offset.Y); err != nil {
+		return fmt.Errorf("failed to translate resource by offset: %w", err)
+	}
+	return nil
+}
+
+// placePinnedChildren places the pinned children at their position from the top-left corner of the
+// content of b, below the header and inside the padding like the laid out children, and grows b to
+// the right and the bottom to fit them with the padding. Their margin is ignored.
+func (r *Resource) placePinnedChildren(b *image.Rectangle, pinned []*Resource, textHeight int, visited map[*Resource]bool) error {
+	r.pinOrigin = image.Point{r.padding.Left, r.headerHeight(textHeight) + r.padding.Top}
+	for _, subResource := range pinned {
+		if !visited[subResource] {
+			if err := subResource.Scale(r, visited); err != nil {
+				return err
+			}
+		}
+		bindings := subResource.GetBindings()
+		if err := subResource.Translation(
+			b.Min.X+r.pinOrigin.X+subResource.pin.X-bindings.Min.X,
+			b.Min.Y+r.pinOrigin.Y+subResource.pin.Y-bindings.Min.Y,
 		); err != nil {
-			return fmt.Errorf("failed to translate border child resource: %w", err)
+			return fmt.Errorf("failed to translate pinned resource: %w", err)
 		}
+		if err := subResource.applyOffset(); err != nil {
+			return err
+		}
+		bindings = subResource.GetBindings()
+		b.Max.X = maxInt(b.Max.X, bindings.Max.X+r.padding.Right)
+		b.Max.Y = maxInt(b.Max.Y, bindings.Max.Y+r.padding.Bottom)
 	}
 	return nil
 }
 
+// stretchChildren grows the child groups to the largest sibling: the height in a horizontal stack
+// and the width in a vertical stack with Align stretch, both with EqualSize. Resources keep their icon size.
+func (r *Resource) stretchChildren() error {
+	return r.stretchResources(r.layoutChildren())
+}
+
+// stretchResources stretches the groups among the children to the size of the largest child
+func (r *Resource) stretchResources(children []*Resource) error {
+	size := image.Point{}
+	for _, subResource := range children {
+		b := subResource.GetBindings()
+		size.X = maxInt(size.X, b.Dx())
+		size.Y = maxInt(size.Y, b.Dy())
+	}
+	for _, subResource := range children {
+		if len(subResource.children) == 0 {
+			continue
+		}
+		b := subResource.GetBindings()
+		width, height := b.Dx(), b.Dy()
+		if r.equalSize || (r.align == "stretch" && r.direction == "vertical") {
+			width = size.X
+		}
+		if r.equalSize || (r.align == "stretch" && r.direction == "horizontal") {
+			height = size.Y
+		}
+		subResource.SetBindings(growBindings(b, width, height))
+		for _, borderChild := range subResource.borderChildren {
+			if err := subResource.placeBorderChild(borderChild); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// growBindings grows b to the given size, keeping its content centered horizontally and adding
+// the extra height at the bottom, below the header and the children.
+func growBindings(b image.Rectangle, width, height int) image.Rectangle {
+	if extra := width - b.Dx(); extra > 0 {
+		b.Min.X -= extra / 2
+		b.Max.X += extra - extra/2
+	}
+	if height > b.Dy() {
+		b.Max.Y = b.Min.Y + height
+	}
+	return b
+}
+
+func (r *Resource) applySpacingOverrides() {
+	if r.marginSpacing != nil {
+		margin := r.marginSpacing.apply(*r.margin)
+		r.margin = &margin
+	}
+	if r.paddingSpacing != nil {
+		padding := Padding(r.paddingSpacing.apply(Margin(*r.padding)))
+		r.padding = &padding
+	}
+}
+
+// applySize resizes the bindings of a group to its fixed width and height. The children stay
+// centered horizontally and the extra height goes below them. A size smaller than the content is ignored.
+func (r *Resource) applySize(b *image.Rectangle) {
+	if r.width > 0 && r.width < b.Dx() {
+		log.Warnf("Width %d of %s is smaller than its content (%d), ignoring it.", r.width, r.label, b.Dx())
+	}
+	if r.height > 0 && r.height < b.Dy() {
+		log.Warnf("Height %d of %s is smaller than its content (%d), ignoring it.", r.height, r.label, b.Dy())
+	}
+	*b = growBindings(*b, maxInt(r.width, r.minWidth), r.height)
+}
+
+// headerHeight returns the height of the icon and the title of a group above its children
+func (r *Resource) headerHeight(textHeight int) int {
+	if r.headerAlign == "center" {
+		return r.iconBounds.Dy() + textHeight
+	}
+	return maxInt(r.iconBounds.Dy(), textHeight)
+}
+
+// expandBindings grows b so that it covers the child with its margin, the group padding and the header.
+func (r *Resource) expandBindings(b *image.Rectangle, subResource *Resource, textHeight int) {
+	bindings := subResource.GetBindings()
+	margin := subResource.GetMargin()
+	b.Min.X = minInt(b.Min.X, bindings.Min.X-margin.Left-r.padding.Left)
+	b.Min.Y = minInt(b.Min.Y, bindings.Min.Y-margin.Top-r.headerHeight(textHeight)-r.padding.Top)
+	b.Max.X = maxInt(b.Max.X, bindings.Max.X+margin.Right+r.padding.Right)
+	b.Max.Y = maxInt(b.Max.Y, bindings.Max.Y+margin.Bottom+r.padding.Bottom)
+}
+
 func (r *Resource) Translation(dx, dy int) error {
 	if r.bindings == nil {
 		return fmt.Errorf("the resource has no binding")
@@ -595,6 +992,15 @@ func (r *Resource) Draw(img *image.RGBA, parent *Resource) (*image.RGBA, error)
 	if img == nil {
 		img = image.NewRGBA(*r.bindings)
 	}
+	if parent == nil {
+		// The paths of all links are known before any of them is drawn
+		links := r.prepareLinks(map[*Resource]bool{}, nil)
+		bundleLinks(links)
+		findLineJumps(links)
+	}
+	if r.divider {
+		return img, r.drawDivider(img, parent)
+	}
 
 	if DEBUG_LAYOUT {
 		r.drawMargin(img)
@@ -605,34 +1011,33 @@ func (r *Resource) Draw(img *image.RGBA, parent *Resource) (*image.RGBA, error)
 	}
 
 	rctSrc := r.iconImage.Bounds()
-	x := image.Rectangle{r.bindings.Min, r.bindings.Min.Add(image.Point{64, 64})}
-	switch r.headerAlign {
-	case "left":
-	case "center":
-		x.Min = x.Min.Add(image.Point{(r.bindings.Dx() - 64) / 2, 0})
-		x.Max = x.Max.Add(image.Point{(r.bindings.Dx() - 64) / 2, 0})
-	case "right":
-		x.Min = x.Min.Add(image.Point{r.bindings.Dx() - 64, 0})
-		x.Max = x.Max.Add(image.Point{r.bindings.Dx() - 64, 0})
+	iconSize := image.Point{64, 64}
+	x := image.Rectangle{r.bindings.Min, r.bindings.Min.Add(iconSize)}
+	switch {
+	case len(r.children) == 0 && !r.iconBounds.Empty():
+		// Resources draw their icon where Scale put it in their bindings
+		x = r.iconBounds.Add(r.bindings.Min)
+	case r.headerAlign == "left":
+	case r.headerAlign == "center":
+		x.Min = x.Min.Add(image.Point{(r.bindings.Dx() - iconSize.X) / 2, 0})
+		x.Max = x.Max.Add(image.Point{(r.bindings.Dx() - iconSize.X) / 2, 0})
+	case r.headerAlign == "right":
+		x.Min = x.Min.Add(image.Point{r.bindings.Dx() - iconSize.X, 0})
+		x.Max = x.Max.Add(image.Point{r.bindings.Dx() - iconSize.X, 0})
 	}
 	if r.iconfill.Type == ICON_FILL_TYPE_RECT {
-		for _x := x.Min.X; _x < x.Max.X; _x++ {
-			for _y := x.Min.Y; _y < x.Max.Y; _y++ {
-				c := img.At(_x, _y)
-				img.Set(_x, _y, _blend_color(c, r.iconfill.Color))
-			}
-		}
+		fillRect(img, x, r.iconfill.Color)
 	}
 	draw.CatmullRom.Scale(img, x, r.iconImage, rctSrc, draw.Over, nil)
 
 	hasIcon := r.iconImage.Bounds().Max.X != 0
 	if parent != nil {
-		if err := r.drawLabel(img, parent, len(r.children) > 0, hasIcon); err != nil {
+		if err := r.drawLabel(img, parent, len(r.children) > 0 || len(r.members) > 0, hasIcon); err != nil {
 			return nil, fmt.Errorf("failed to draw label: %w", err)
 		}
 	}
 
-	for _, subResource := range r.children {
+	for _, subResource := range r.drawnChildren() {
 		if _, err := subResource.Draw(img, r); err != nil {
 			return nil, fmt.Errorf("failed to draw child resource: %w", err)
 		}
@@ -642,11 +1047,14 @@ func (r *Resource) Draw(img *image.RGBA, parent *Resource) (*image.RGBA, error)
 			return nil, fmt.Errorf("failed to draw border child resource: %w", err)
 		}
 	}
+	// Overlays are drawn over the tree since their members may be in any subtree
+	for _, overlay := range r.overlays {
+		if _, err := overlay.Draw(img, r); err != nil {
+			return nil, fmt.Errorf("failed to draw overlay: %w", err)
+		}
+	}
 	r.drawn = true
 
-	// Pre-sort links before drawing
-	r.sortAllLinks()
-
 	for _, v := range r.links {
 		source := *v.Source
 		target := *v.Target
@@ -666,12 +1074,7 @@ func (r *Resource) sortAllLinks() {
 	linkGroups := make(map[string][]*Link)
 
 	for _, link := range r.links {
-		var key string
-		if link.Source == r {
-			key = fmt.Sprintf("%d", link.SourcePosition)
-		} else if link.Target == r {
-			key = fmt.Sprintf("%d", link.TargetPosition)
-		}
+		key := link.positionKey(r)
 		if key != "" {
 			links, ok := linkGroups[key]
 			if !ok {
@@ -692,8 +1095,8 @@ func (r *Resource) sortAllLinks() {
 
 		log.Infof("Group %s: sorting %d links", key, len(links))
 
-		// Convert key to position
-		position, _ := strconv.Atoi(key)
+		// Convert key to position, the side of an anchor
+		position, _ := strconv.Atoi(strings.Split(key, "@")[0])
 
 		// Log order before sorting
 		for i, link := range links {
@@ -712,17 +1115,8 @@ func (r *Resource) sortAllLinks() {
 		}
 
 		sort.Slice(links, func(i, j int) bool {
-			var pt1, pt2 image.Point
-			if links[i].Source == r {
-				pt1, _ = calcPosition(links[i].Target.GetBindings(), links[i].TargetPosition)
-			} else {
-				pt1, _ = calcPosition(links[i].Source.GetBindings(), links[i].SourcePosition)
-			}
-			if links[j].Source == r {
-				pt2, _ = calcPosition(links[j].Target.GetBindings(), links[j].TargetPosition)
-			} else {
-				pt2, _ = calcPosition(links[j].Source.GetBindings(), links[j].SourcePosition)
-			}
+			pt1 := links[i].endPoint(links[i].Source != r)
+			pt2 := links[j].endPoint(links[j].Source != r)
 
 			// Sort by perpendicular direction of direction vector
 			direction := getDirectionVectorStatic(int(position))
@@ -765,12 +1159,7 @@ func (r *Resource) updateLinksOrder(sortedLinks []*Link, groupKey string) {
 
 	// Add non-sort target links first
 	for _, link := range r.links {
-		var key string
-		if link.Source == r {
-			key = fmt.Sprintf("%d", link.SourcePosition)
-		} else if link.Target == r {
-			key = fmt.Sprintf("%d", link.TargetPosition)
-		}
+		key := link.positionKey(r)
 
 		if key != groupKey {
 			newLinks = append(newLinks, link)
@@ -812,66 +1201,76 @@ func (r *Resource) drawFrame(img *image.RGBA) {
 	x2 := r.bindings.Max.X
 	y1 := r.bindings.Min.Y
 	y2 := r.bindings.Max.Y
-	for x := x1 - WIDTH + 1; x < x2+WIDTH-1; x++ {
-		for y := y1 - WIDTH + 1; y < y2+WIDTH-1; y++ {
-			c := img.At(x, y)
-			if x <= x1 || x >= x2-1 || y <= y1 || y >= y2-1 {
-				// Set border
-				switch r.borderType {
-				case BORDER_TYPE_STRAIGHT:
-					img.Set(x, y, _blend_color(c, r.borderColor))
-				case BORDER_TYPE_DASHED:
-					if (x+y)%9 <= 5 {
-						img.Set(x, y, _blend_color(c, r.borderColor))
-					}
-				}
-			} else {
-				// Set background
-				img.Set(x, y, _blend_color(c, r.fillColor))
-				if DEBUG_LAYOUT {
-					img.Set(x, y, _blend_color(c, color.RGBA{255, 255, 255, 255}))
-				}
-				//img.Set(x, y, fill_color)
-			}
+	w := 0.0
+	if r.borderColor != nil {
+		w = float64(r.borderWidth)
+	}
+	// The background covers the pixels inside the first and the last pixels of the bindings, and
+	// the border grows outwards from them
+	left, right := float64(x1)+0.5, float64(x2)-1.5
+	top, bottom := float64(y1)+0.5, float64(y2)-1.5
+	radius := r.frameRadius()
+	// The corners of the border and of the shadow are concentric with those of the background
+	outset := func(d float64) polygon {
+		if radius == 0 {
+			return roundedRectangle(left-d, top-d, right+d, bottom+d, 0)
 		}
+		return roundedRectangle(left-d, top-d, right+d, bottom+d, radius+d)
+	}
+	if r.shadow != nil {
+		r.drawShadow(img, outset(w))
 	}
-}
 
-func (r *Resource) drawPadding(img *image.RGBA) {
-	x1 := r.bindings.Min.X
-	x2 := r.bindings.Max.X
-	y1 := r.bindings.Min.Y
-	y2 := r.bindings.Max.Y
-	for x := x1 - WIDTH + 1; x < x2+WIDTH-1; x++ {
-		for y := y1 - WIDTH + 1; y < y2+WIDTH-1; y++ {
-			c := img.At(x, y)
-			img.Set(x, y, _blend_color(c, color.RGBA{0, 255, 0, 127}))
-		}
+	// Set background
+	background := image.Rect(x1+1, y1+1, x2-1, y2-1)
+	switch {
+	case r.gradient != nil:
+		fillPolygonsWith(img, []polygon{outset(0)}, newLinearGradient(r.gradient, background))
+	case radius > 0:
+		fillPolygons(img, []polygon{outset(0)}, r.fillColor)
+	default:
+		fillRect(img, background, r.fillColor)
+	}
+	if DEBUG_LAYOUT {
+		fillRect(img, background, color.RGBA{255, 255, 255, 255})
+	}
+
+	// Set border
+	if r.borderColor == nil {
+		return
 	}
-	x1 = r.bindings.Min.X + r.padding.Left
-	x2 = r.bindings.Max.X - r.padding.Right
-	y1 = r.bindings.Min.Y + r.padding.Top
-	y2 = r.bindings.Max.Y - r.padding.Bottom
-	for x := x1 - WIDTH + 1; x < x2+WIDTH-1; x++ {
-		for y := y1 - WIDTH + 1; y < y2+WIDTH-1; y++ {
-			c := img.At(x, y)
-			img.Set(x, y, _blend_color(c, color.RGBA{255, 255, 255, 255}))
+	outline := outset(w / 2)
+	polygons := []polygon{}
+	if pattern := r.borderPattern(); len(pattern) == 0 {
+		polygons = strokePolyline(outline, w, JOIN_MITER, true)
+	} else {
+		// Dashes run on around the corners, from the top left one
+		for _, dash := range dashPolyline(append(outline, outline[0]), pattern, 0) {
+			polygons = append(polygons, strokePolyline(dash, w, JOIN_MITER, false)...)
 		}
 	}
+	fillPolygons(img, polygons, *r.borderColor)
+}
 
+func (r *Resource) drawPadding(img *image.RGBA) {
+	fillRect(img, r.bindings.Inset(1-WIDTH), color.RGBA{0, 255, 0, 127})
+	inner := image.Rect(
+		r.bindings.Min.X+r.padding.Left,
+		r.bindings.Min.Y+r.padding.Top,
+		r.bindings.Max.X-r.padding.Right,
+		r.bindings.Max.Y-r.padding.Bottom,
+	)
+	fillRect(img, inner.Inset(1-WIDTH), color.RGBA{255, 255, 255, 255})
 }
 
 func (r *Resource) drawMargin(img *image.RGBA) {
-	x1 := r.bindings.Min.X - r.margin.Left
-	x2 := r.bindings.Max.X + r.margin.Right
-	y1 := r.bindings.Min.Y - r.margin.Top
-	y2 := r.bindings.Max.Y + r.margin.Bottom
-	for x := x1 - WIDTH + 1; x < x2+WIDTH-1; x++ {
-		for y := y1 - WIDTH + 1; y < y2+WIDTH-1; y++ {
-			c := img.At(x, y)
-			img.Set(x, y, _blend_color(c, color.RGBA{255, 255, 0, 255}))
-		}
-	}
+	outer := image.Rect(
+		r.bindings.Min.X-r.margin.Left,
+		r.bindings.Min.Y-r.margin.Top,
+		r.bindings.Max.X+r.margin.Right,
+		r.bindings.Max.Y+r.margin.Bottom,
+	)
+	fillRect(img, outer.Inset(1-WIDTH), color.RGBA{255, 255, 0, 255})
 }
 
 func (r *Resource) drawLabel(img *image.RGBA, parent *Resource, hasChild, hasIcon bool) error {
@@ -893,8 +1292,12 @@ func (r *Resource) drawLabel(img *image.RGBA, parent *Resource, hasChild, hasIco
 		h := textBindings.Max.Y - textBindings.Min.Y + fixed.I(lineOffset)
 
 		p := r.bindings.Min.Add(image.Point{0, r.iconBounds.Max.Y})
+		if hasIcon {
+			// Below the box the icon is centered in
+			p.Y = maxInt(p.Y, r.bindings.Max.Y)
+		}
 
-		point := fixed.Point26_6{fixed.I(p.X) - (w-fixed.I(r.bindings.Dx()))/2, fixed.I(p.Y+10) + h}
+		point := fixed.Point26_6{X: fixed.I(p.X) - (w-fixed.I(r.bindings.Dx()))/2, Y: fixed.I(p.Y+10) + h}
 		if hasChild {
 			iconHeight := r.iconBounds.Max.Y
 			if iconHeight == 0 {
@@ -918,7 +1321,7 @@ func (r *Resource) drawLabel(img *image.RGBA, parent *Resource, hasChild, hasIco
 					iconHeight - padding + lineOffset,
 				})
 			}
-			point = fixed.Point26_6{fixed.I(p.X), fixed.I(p.Y)}
+			point = fixed.Point26_6{X: fixed.I(p.X), Y: fixed.I(p.Y)}
 		}
 
 		d := &font.Drawer{
@@ -429,3 +429,225 @@ func TestSetPadding(t *testing.T) {
 			result.Top, result.Right, result.Bottom, result.Left)
 	}
 }
+
+func TestSpacingOverride(t *testing.T) {
+	left := 60
+	top := 0
+	resource := new(Resource).Init()
+	if err := resource.AddChild(new(Resource).Init()); err != nil {
+		t.Fatalf("AddChild failed: %v", err)
+	}
+	resource.SetPaddingOverride(Spacing{Left: &left})
+	resource.SetMarginOverride(Spacing{Top: &top})
+	if err := resource.Scale(nil, nil); err != nil {
+		t.Fatalf("Scale failed: %v", err)
+	}
+
+	if resource.GetPadding() != (Padding{20, 45, 20, 60}) {
+		t.Errorf("expected padding {20 45 20 60}, got %v", resource.GetPadding())
+	}
+	if resource.GetMargin() != (Margin{0, 15, 20, 15}) {
+		t.Errorf("expected margin {0 15 20 15}, got %v", resource.GetMargin())
+	}
+}
+
+func TestSetSize(t *testing.T) {
+	// Group: the content is 90x40 including the padding
+	group := new(Resource).Init()
+	if err := group.AddChild(new(Resource).Init()); err != nil {
+		t.Fatalf("AddChild failed: %v", err)
+	}
+	group.SetSize(200, 100)
+	if err := group.Scale(nil, nil); err != nil {
+		t.Fatalf("Scale failed: %v", err)
+	}
+	if group.GetBindings() != image.Rect(-100, -20, 100, 80) {
+		t.Errorf("expected group bindings %v, got %v", image.Rect(-100, -20, 100, 80), group.GetBindings())
+	}
+
+	// A size smaller than the content is ignored
+	small := new(Resource).Init()
+	if err := small.AddChild(new(Resource).Init()); err != nil {
+		t.Fatalf("AddChild failed: %v", err)
+	}
+	small.SetSize(10, 10)
+	if err := small.Scale(nil, nil); err != nil {
+		t.Fatalf("Scale failed: %v", err)
+	}
+	if small.GetBindings() != image.Rect(-45, -20, 45, 20) {
+		t.Errorf("expected group bindings %v, got %v", image.Rect(-45, -20, 45, 20), small.GetBindings())
+	}
+
+	// Resource: the icon keeps its size, centered in the box
+	resource := new(Resource).Init()
+	resource.iconImage = image.NewRGBA(image.Rect(0, 0, 64, 64))
+	resource.SetIconBounds(image.Rect(0, 0, 64, 64))
+	resource.SetBindings(image.Rect(0, 0, 64, 64))
+	resource.SetSize(0, 96)
+	resource.SetMinWidth(128)
+	if err := resource.Scale(nil, nil); err != nil {
+		t.Fatalf("Scale failed: %v", err)
+	}
+	if resource.GetBindings() != image.Rect(0, 0, 128, 96) {
+		t.Errorf("expected resource bindings %v, got %v", image.Rect(0, 0, 128, 96), resource.GetBindings())
+	}
+	if resource.iconBounds != image.Rect(32, 16, 96, 80) {
+		t.Errorf("expected icon bounds %v, got %v", image.Rect(32, 16, 96, 80), resource.iconBounds)
+	}
+
+	// A box smaller than the icon scales it down with its aspect ratio
+	for _, tc := range []struct {
+		icon, box image.Point
+		expected  image.Rectangle
+	}{
+		{image.Pt(64, 64), image.Pt(200, 64), image.Rect(68, 0, 132, 64)},
+		{image.Pt(64, 64), image.Pt(32, 100), image.Rect(0, 34, 32, 66)},
+		{image.Pt(64, 32), image.Pt(48, 48), image.Rect(0, 12, 48, 36)},
+	} {
+		if got := fitIcon(tc.icon, tc.box); got != tc.expected {
+			t.Errorf("fitIcon(%v, %v): expected %v, got %v", tc.icon, tc.box, tc.expected, got)
+		}
+	}
+}
+
+func newStretchGroup(t *testing.T, direction string, cells ...*Resource) *Resource {
+	group := new(Resource).Init()
+	group.SetDirection(direction)
+	for _, c := range cells {
+		if err := group.AddChild(c); err != nil {
+			t.Fatalf("AddChild failed: %v", err)
+		}
+	}
+	return group
+}
+
+func TestAlignStretch(t *testing.T) {
+	short := newStretchGroup(t, "horizontal", newGridCell(50, 50))
+	tall := newStretchGroup(t, "vertical", newGridCell(50, 50), newGridCell(50, 50))
+	icon := newGridCell(64, 64)
+	stack := HorizontalStack{}.Init()
+	stack.SetAlign("stretch")
+	for _, c := range []*Resource{short, tall, icon} {
+		if err := stack.AddChild(c); err != nil {
+			t.Fatalf("AddChild failed: %v", err)
+		}
+	}
+	if err := stack.Scale(nil, nil); err != nil {
+		t.Fatalf("Scale failed: %v", err)
+	}
+
+	if short.GetBindings().Dy() != tall.GetBindings().Dy() {
+		t.Errorf("expected same height, got %v and %v", short.GetBindings(), tall.GetBindings())
+	}
+	if short.GetBindings().Min.Y != tall.GetBindings().Min.Y {
+		t.Errorf("expected same top, got %v and %v", short.GetBindings(), tall.GetBindings())
+	}
+	if icon.GetBindings().Dy() != 64 {
+		t.Errorf("expected resources to keep their size, got %v", icon.GetBindings())
+	}
+}
+
+func TestEqualSize(t *testing.T) {
+	wide := newStretchGroup(t, "horizontal", newGridCell(50, 50), newGridCell(50, 50))
+	tall := newStretchGroup(t, "vertical", newGridCell(50, 50), newGridCell(50, 50))
+	stack := VerticalStack{}.Init()
+	stack.SetEqualSize(true)
+	for _, c := range []*Resource{wide, tall} {
+		if err := stack.AddChild(c); err != nil {
+			t.Fatalf("AddChild failed: %v", err)
+		}
+	}
+	if err := stack.Scale(nil, nil); err != nil {
+		t.Fatalf("Scale failed: %v", err)
+	}
+
+	if wide.GetBindings().Size() != tall.GetBindings().Size() {
+		t.Errorf("expected same size, got %v and %v", wide.GetBindings(), tall.GetBindings())
+	}
+}
+
+func TestReverseDirection(t *testing.T) {
+	testCases := []struct {
+		direction string
+		expected  []image.Point
+	}{
+		{"horizontal", []image.Point{{0, 0}, {50, 0}, {100, 0}}},
+		{"horizontal-reverse", []image.Point{{100, 0}, {50, 0}, {0, 0}}},
+		{"vertical-reverse", []image.Point{{0, 100}, {0, 50}, {0, 0}}},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.direction, func(t *testing.T) {
+			stack := HorizontalStack{}.Init()
+			stack.SetDirection(tc.direction)
+			cells := []*Resource{newGridCell(50, 50), newGridCell(50, 50), newGridCell(50, 50)}
+			for _, c := range cells {
+				if err := stack.AddChild(c); err != nil {
+					t.Fatalf("AddChild failed: %v", err)
+				}
+			}
+			if err := stack.Scale(nil, nil); err != nil {
+				t.Fatalf("Scale failed: %v", err)
+			}
+			// Positions relative to the first placed child
+			origin := cells[0].GetBindings().Min.Sub(tc.expected[0])
+			for i, c := range cells {
+				if c.GetBindings().Min.Sub(origin) != tc.expected[i] {
+					t.Errorf("cell %d: expected position %v, got %v", i, tc.expected[i], c.GetBindings().Min.Sub(origin))
+				}
+			}
+		})
+	}
+}
+
+func TestOffsetAndPin(t *testing.T) {
+	stack := HorizontalStack{}.Init()
+	stack.SetAlign("top")
+	cells := []*Resource{newGridCell(50, 50), newGridCell(50, 50), newGridCell(50, 50), newGridCell(50, 50)}
+	cells[1].SetOffset(image.Point{0, 20})
+	cells[3].SetPin(image.Point{200, 100})
+	for _, c := range cells {
+		if err := stack.AddChild(c); err != nil {
+			t.Fatalf("AddChild failed: %v", err)
+		}
+	}
+	if err := stack.Scale(nil, nil); err != nil {
+		t.Fatalf("Scale failed: %v", err)
+	}
+
+	// The offset doesn't move the following siblings and the pinned child leaves no gap in the stack
+	expected := []image.Point{{0, 0}, {50, 20}, {100, 0}, {200, 100}}
+	for i, c := range cells {
+		if c.GetBindings().Min != expected[i] {
+			t.Errorf("cell %d: expected position %v, got %v", i, expected[i], c.GetBindings().Min)
+		}
+	}
+	if stack.GetBindings() != image.Rect(0, 0, 250, 150) {
+		t.Errorf("expected stack bindings %v, got %v", image.Rect(0, 0, 250, 150), stack.GetBindings())
+	}
+}
+
+func TestPinOrigin(t *testing.T) {
+	group := new(Resource).Init()
+	group.SetIconBounds(image.Rect(0, 0, 64, 64))
+	placed := newGridCell(50, 50)
+	pinned := newGridCell(20, 20)
+	pinned.SetPin(image.Point{0, 0})
+	for _, c := range []*Resource{placed, pinned} {
+		if err := group.AddChild(c); err != nil {
+			t.Fatalf("AddChild failed: %v", err)
+		}
+	}
+	if err := group.Scale(nil, nil); err != nil {
+		t.Fatalf("Scale failed: %v", err)
+	}
+
+	// The pin is measured from the content below the header, like the laid out children
+	padding := group.GetPadding()
+	origin := group.GetBindings().Min.Add(image.Point{padding.Left, 64 + padding.Top})
+	if group.GetPinOrigin() != origin || pinned.GetBindings().Min != origin {
+		t.Errorf("expected the pinned child at the content origin %v, got %v (origin %v)", origin, pinned.GetBindings().Min, group.GetPinOrigin())
+	}
+	if placed.GetBindings().Min.Y != pinned.GetBindings().Min.Y {
+		t.Errorf("expected the laid out child at the same height, got %v and %v", placed.GetBindings(), pinned.GetBindings())
+	}
+}
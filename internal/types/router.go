@@ -0,0 +1,489 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package types
+
+import (
+	"container/heap"
+	"image"
+	"sort"
+
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	ROUTE_STUB      = 20      // length of the first and the last segments of a routed link
+	ROUTE_MARGIN    = 10      // between a routed link and the resources around it
+	ROUTE_SPACING   = 8       // between parallel segments of different routed links
+	ROUTE_BEND_COST = 1 << 24 // a bend costs more than any length, so the fewest bends win
+)
+
+// Router holds the resources of a diagram and the links already routed in it, so that links of
+// Type orthogonal-routed go around the resources and keep parallel segments apart.
+type Router struct {
+	resources []*Resource
+	segments  []routedSegment
+	grid      *routeGrid // built for the first routed link, once the layout is done
+}
+
+type routedSegment struct {
+	link   *Link
+	p1, p2 image.Point
+}
+
+type ROUTE_AREA_TYPE int
+
+const (
+	ROUTE_AREA_VISIBLE  ROUTE_AREA_TYPE = iota // a resource with its label, avoided by the other links
+	ROUTE_AREA_ENDPOINT                        // a resource without its label, avoided by its own links
+	ROUTE_AREA_HEADER                          // the header of a group, avoided with AvoidHeaders
+)
+
+// routeArea is an area of the diagram that routed links may have to go around
+type routeArea struct {
+	area     image.Rectangle
+	resource *Resource
+	kind     ROUTE_AREA_TYPE
+}
+
+// NewRouter collects the resources drawn from root as obstacles for routed links
+func NewRouter(root *Resource) *Router {
+	return &Router{resources: collectDescendants(root, nil)}
+}
+
+func (l *Link) SetRouter(router *Router) {
+	l.router = router
+}
+
+func (l *Link) SetAvoidHeaders(avoid bool) {
+	l.avoidHeaders = avoid
+}
+
+// routeAreas returns the areas around the resources that links may have to avoid
+func routeAreas(resources []*Resource) []routeArea {
+	inflate := func(b image.Rectangle) image.Rectangle {
+		return image.Rect(b.Min.X-ROUTE_MARGIN, b.Min.Y-ROUTE_MARGIN, b.Max.X+ROUTE_MARGIN, b.Max.Y+ROUTE_MARGIN)
+	}
+	areas := []routeArea{}
+	for _, r := range resources {
+		if r.bindings == nil || r.divider {
+			continue
+		}
+		if len(r.children) != 0 {
+			if header, ok := r.headerArea(); ok {
+				areas = append(areas, routeArea{header, r, ROUTE_AREA_HEADER})
+			}
+			continue
+		}
+		areas = append(areas, routeArea{inflate(r.GetBindings()), r, ROUTE_AREA_ENDPOINT})
+		visible, err := r.visibleBounds()
+		if err != nil {
+			visible = r.GetBindings()
+		}
+		areas = append(areas, routeArea{inflate(visible), r, ROUTE_AREA_VISIBLE})
+	}
+	return areas
+}
+
+// routeGrid returns the grid of the areas of the diagram. The router builds it once and shares it
+// between its links, since the areas only depend on the layout.
+func (l *Link) routeGrid() *routeGrid {
+	if l.router == nil {
+		return newRouteGrid(routeAreas([]*Resource{l.Source, l.Target}))
+	}
+	if l.router.grid == nil {
+		l.router.grid = newRouteGrid(routeAreas(l.router.resources))
+	}
+	return l.router.grid
+}
+
+// routeObstacles returns which areas of the grid the link must not cross: the resources with
+// their label, the source and the target, and optionally the headers of the groups. It also
+// returns the overlays, which aren't in the grid. Areas around the ends of the link are left out.
+func (l *Link) routeObstacles(grid *routeGrid, start, end image.Point) ([]bool, []image.Rectangle) {
+	avoided := func(area image.Rectangle) bool {
+		return !strictlyInside(start, area) && !strictlyInside(end, area) && !area.Empty()
+	}
+	enabled := make([]bool, len(grid.areas))
+	for i, a := range grid.areas {
+		endpoint := a.resource == l.Source || a.resource == l.Target
+		switch a.kind {
+		case ROUTE_AREA_VISIBLE:
+			enabled[i] = !endpoint
+		case ROUTE_AREA_ENDPOINT:
+			enabled[i] = endpoint
+		case ROUTE_AREA_HEADER:
+			enabled[i] = l.avoidHeaders && !endpoint
+		}
+		enabled[i] = enabled[i] && avoided(a.area)
+	}
+	extra := []image.Rectangle{}
+	for _, obstacle := range l.obstacles {
+		if area := obstacle.GetBindings(); avoided(area) {
+			extra = append(extra, area)
+		}
+	}
+	return enabled, extra
+}
+
+// headerArea returns the area of the icon and the title of a group
+func (r *Resource) headerArea() (image.Rectangle, bool) {
+	if r.label == "" && r.iconBounds.Empty() {
+		return image.Rectangle{}, false
+	}
+	fontFace, err := r.prepareFontFace(true, nil)
+	if err != nil {
+		return image.Rectangle{}, false
+	}
+	textWidth, textHeight := r.labelSize(fontFace)
+	b := r.GetBindings()
+	width := minInt(r.iconBounds.Dx()+textWidth, b.Dx())
+	x := b.Min.X
+	switch r.headerAlign {
+	case "center":
+		x = b.Min.X + (b.Dx()-width)/2
+	case "right":
+		x = b.Max.X - width
+	}
+	return image.Rect(x, b.Min.Y, x+width, b.Min.Y+r.headerHeight(textHeight)), true
+}
+
+func strictlyInside(p image.Point, r image.Rectangle) bool {
+	return p.X > r.Min.X && p.X < r.Max.X && p.Y > r.Min.Y && p.Y < r.Max.Y
+}
+
+// route returns the control points of a path from sourcePt to targetPt that goes around the
+// obstacles with the fewest bends, then the shortest length. It returns false if there is none.
+func (l *Link) route(sourcePt, targetPt image.Point) ([]image.Point, bool) {
+	sourceDir := l.getDirectionVector(int(l.SourcePosition))
+	targetDir := l.getDirectionVector(int(l.TargetPosition))
+	sourceStep := image.Point{int(sourceDir.X), int(sourceDir.Y)}
+	targetStep := image.Point{int(targetDir.X), int(targetDir.Y)}
+	start := sourcePt.Add(sourceStep.Mul(ROUTE_STUB))
+	end := targetPt.Add(targetStep.Mul(ROUTE_STUB))
+	grid := l.routeGrid()
+	enabled, extra := l.routeObstacles(grid, start, end)
+
+	path, ok := grid.findRoute(start, end, sourceStep, image.Point{}.Sub(targetStep), enabled, extra)
+	if !ok {
+		return nil, false
+	}
+	pts := simplifyPath(append(append([]image.Point{sourcePt}, path...), targetPt))
+	if l.router != nil {
+		obstacles := append([]image.Rectangle{}, extra...)
+		for i, a := range grid.areas {
+			if enabled[i] {
+				obstacles = append(obstacles, a.area)
+			}
+		}
+		pts = l.router.spread(l, pts, obstacles)
+	}
+	return pts[1 : len(pts)-1], true
+}
+
+type routeState struct {
+	node int // index in the grid: x + y*len(xs)
+	dir  int // index in routeDirs
+}
+
+var routeDirs = []image.Point{{0, -1}, {1, 0}, {0, 1}, {-1, 0}}
+
+// routeGrid indexes the areas of a diagram by the cells between their edges, so that the search
+// of a route only looks at the areas around each step
+type routeGrid struct {
+	areas []routeArea
+	xs    []int
+	ys    []int
+	cover [][]int // indices of the areas covering each cell, by x + y*(len(xs)-1)
+}
+
+func newRouteGrid(areas []routeArea) *routeGrid {
+	g := &routeGrid{areas: areas}
+	xs, ys := []int{}, []int{}
+	for _, a := range areas {
+		xs = append(xs, a.area.Min.X, a.area.Max.X)
+		ys = append(ys, a.area.Min.Y, a.area.Max.Y)
+	}
+	g.xs = uniqueSorted(xs)
+	g.ys = uniqueSorted(ys)
+	if len(g.xs) < 2 || len(g.ys) < 2 {
+		return g
+	}
+	columns := len(g.xs) - 1
+	g.cover = make([][]int, columns*(len(g.ys)-1))
+	for i, a := range areas {
+		if a.area.Empty() {
+			continue
+		}
+		x1, x2 := sort.SearchInts(g.xs, a.area.Min.X), sort.SearchInts(g.xs, a.area.Max.X)
+		y1, y2 := sort.SearchInts(g.ys, a.area.Min.Y), sort.SearchInts(g.ys, a.area.Max.Y)
+		for y := y1; y < y2; y++ {
+			for x := x1; x < x2; x++ {
+				g.cover[x+y*columns] = append(g.cover[x+y*columns], i)
+			}
+		}
+	}
+	return g
+}
+
+// covering returns the areas covering the point (x/2, y/2), which is never on an edge as x and y
+// are odd or between two edges
+func (g *routeGrid) covering(x, y int) []int {
+	cell := func(values []int, v int) int {
+		return sort.Search(len(values), func(i int) bool { return 2*values[i] > v }) - 1
+	}
+	cx, cy := cell(g.xs, x), cell(g.ys, y)
+	if cx < 0 || cy < 0 || cx >= len(g.xs)-1 || cy >= len(g.ys)-1 {
+		return nil
+	}
+	return g.cover[cx+cy*(len(g.xs)-1)]
+}
+
+// findRoute searches a route around the obstacles, see routeGrid.findRoute
+func findRoute(start, end, startDir, endDir image.Point, obstacles []image.Rectangle) ([]image.Point, bool) {
+	areas := make([]routeArea, len(obstacles))
+	for i, o := range obstacles {
+		areas[i] = routeArea{area: o}
+	}
+	return newRouteGrid(areas).findRoute(start, end, startDir, endDir, nil, nil)
+}
+
+// findRoute searches a sparse orthogonal visibility graph whose lines go through start, end and
+// the edges of the obstacles: the enabled areas of the grid, all of them if enabled is nil, and
+// the extra ones. The path leaves start in startDir and arrives at end in endDir.
+func (g *routeGrid) findRoute(start, end, startDir, endDir image.Point, enabled []bool, extra []image.Rectangle) ([]image.Point, bool) {
+	xs := append([]int{start.X, end.X}, g.xs...)
+	ys := append([]int{start.Y, end.Y}, g.ys...)
+	for _, o := range extra {
+		xs = append(xs, o.Min.X, o.Max.X)
+		ys = append(ys, o.Min.Y, o.Max.Y)
+	}
+	xs = uniqueSorted(xs)
+	ys = uniqueSorted(ys)
+	point := func(node int) image.Point {
+		return image.Point{xs[node%len(xs)], ys[node/len(xs)]}
+	}
+	index := func(values []int, v int) int {
+		return sort.SearchInts(values, v)
+	}
+	// Only the areas covering the cell next to a point or a segment can contain it
+	anyCandidate := func(x, y int, f func(o image.Rectangle) bool) bool {
+		for _, i := range g.covering(x, y) {
+			if (enabled == nil || enabled[i]) && f(g.areas[i].area) {
+				return true
+			}
+		}
+		for _, o := range extra {
+			if f(o) {
+				return true
+			}
+		}
+		return false
+	}
+	blocked := func(p image.Point) bool {
+		return anyCandidate(2*p.X+1, 2*p.Y+1, func(o image.Rectangle) bool {
+			return strictlyInside(p, o)
+		})
+	}
+	// A segment between two neighbors of the grid crosses an obstacle if its middle is inside,
+	// since the edges of the obstacles are lines of the grid
+	crosses := func(a, b image.Point) bool {
+		x, y := a.X+b.X, 2*a.Y+1
+		if a.X == b.X {
+			x, y = 2*a.X+1, a.Y+b.Y
+		}
+		return anyCandidate(x, y, func(o image.Rectangle) bool {
+			if a.X == b.X {
+				return a.X > o.Min.X && a.X < o.Max.X && minInt(a.Y, b.Y) < o.Max.Y && maxInt(a.Y, b.Y) > o.Min.Y
+			}
+			return a.Y > o.Min.Y && a.Y < o.Max.Y && minInt(a.X, b.X) < o.Max.X && maxInt(a.X, b.X) > o.Min.X
+		})
+	}
+	dirIndex := func(d image.Point) int {
+		for i, v := range routeDirs {
+			if v == d {
+				return i
+			}
+		}
+		return 0
+	}
+
+	startNode := index(xs, start.X) + index(ys, start.Y)*len(xs)
+	endNode := index(xs, end.X) + index(ys, end.Y)*len(xs)
+	endDirIndex := dirIndex(endDir)
+	cost := map[routeState]int{}
+	prev := map[routeState]routeState{}
+	queue := &routeQueue{}
+	first := routeState{startNode, dirIndex(startDir)}
+	cost[first] = 0
+	heap.Push(queue, routeItem{first, 0})
+	var last *routeState
+	for queue.Len() > 0 {
+		item := heap.Pop(queue).(routeItem)
+		if item.cost > cost[item.state] {
+			continue
+		}
+		if item.state.node == endNode && item.state.dir == endDirIndex {
+			last = &item.state
+			break
+		}
+		p := point(item.state.node)
+		for d, step := range routeDirs {
+			if (d+2)%4 == item.state.dir {
+				continue
+			}
+			next := routeState{item.state.node, d}
+			c := item.cost
+			if d != item.state.dir {
+				// Turning on the spot
+				c += ROUTE_BEND_COST
+			} else {
+				xi, yi := index(xs, p.X)+step.X, index(ys, p.Y)+step.Y
+				if xi < 0 || xi >= len(xs) || yi < 0 || yi >= len(ys) {
+					continue
+				}
+				next.node = xi + yi*len(xs)
+				q := point(next.node)
+				if (next.node != endNode && blocked(q)) || crosses(p, q) {
+					continue
+				}
+				c += abs(q.X-p.X) + abs(q.Y-p.Y)
+			}
+			if old, ok := cost[next]; ok && old <= c {
+				continue
+			}
+			cost[next] = c
+			prev[next] = item.state
+			heap.Push(queue, routeItem{next, c})
+		}
+	}
+	if last == nil {
+		return nil, false
+	}
+
+	path := []image.Point{}
+	for s := *last; ; s = prev[s] {
+		if len(path) == 0 || path[len(path)-1] != point(s.node) {
+			path = append(path, point(s.node))
+		}
+		if s == first {
+			break
+		}
+	}
+	for i, j := 0, len(path)-1; i < j; i, j = i+1, j-1 {
+		path[i], path[j] = path[j], path[i]
+	}
+	return path, true
+}
+
+type routeItem struct {
+	state routeState
+	cost  int
+}
+
+type routeQueue []routeItem
+
+func (q routeQueue) Len() int            { return len(q) }
+func (q routeQueue) Less(i, j int) bool  { return q[i].cost < q[j].cost }
+func (q routeQueue) Swap(i, j int)       { q[i], q[j] = q[j], q[i] }
+func (q *routeQueue) Push(x interface{}) { *q = append(*q, x.(routeItem)) }
+func (q *routeQueue) Pop() interface{} {
+	old := *q
+	item := old[len(old)-1]
+	*q = old[:len(old)-1]
+	return item
+}
+
+func uniqueSorted(values []int) []int {
+	sort.Ints(values)
+	result := []int{}
+	for i, v := range values {
+		if i == 0 || v != values[i-1] {
+			result = append(result, v)
+		}
+	}
+	return result
+}
+
+// simplifyPath removes the points in the middle of straight lines
+func simplifyPath(pts []image.Point) []image.Point {
+	result := []image.Point{}
+	for _, p := range pts {
+		if len(result) > 0 && result[len(result)-1] == p {
+			continue
+		}
+		if len(result) >= 2 {
+			a, b := result[len(result)-2], result[len(result)-1]
+			if (a.X == b.X && b.X == p.X) || (a.Y == b.Y && b.Y == p.Y) {
+				result[len(result)-1] = p
+				continue
+			}
+		}
+		result = append(result, p)
+	}
+	return result
+}
+
+// spread moves the inner segments of the path that run along a segment of another routed link,
+// then records the segments of the path. The first and the last segments stay attached to the resources.
+func (router *Router) spread(l *Link, pts []image.Point, obstacles []image.Rectangle) []image.Point {
+	overlaps := func(p1, p2 image.Point) bool {
+		for _, s := range router.segments {
+			if s.link == l {
+				continue
+			}
+			if p1.Y == p2.Y && s.p1.Y == s.p2.Y && abs(p1.Y-s.p1.Y) < ROUTE_SPACING &&
+				minInt(p1.X, p2.X) < maxInt(s.p1.X, s.p2.X) && maxInt(p1.X, p2.X) > minInt(s.p1.X, s.p2.X) {
+				return true
+			}
+			if p1.X == p2.X && s.p1.X == s.p2.X && abs(p1.X-s.p1.X) < ROUTE_SPACING &&
+				minInt(p1.Y, p2.Y) < maxInt(s.p1.Y, s.p2.Y) && maxInt(p1.Y, p2.Y) > minInt(s.p1.Y, s.p2.Y) {
+				return true
+			}
+		}
+		return false
+	}
+	crosses := func(p1, p2 image.Point) bool {
+		for _, o := range obstacles {
+			if minInt(p1.X, p2.X) < o.Max.X && maxInt(p1.X, p2.X) > o.Min.X && minInt(p1.Y, p2.Y) < o.Max.Y && maxInt(p1.Y, p2.Y) > o.Min.Y {
+				return true
+			}
+		}
+		return false
+	}
+	keepsDirection := func(from, old, new int) bool {
+		return (old-from)*(new-from) > 0
+	}
+
+	for i := 1; i+2 < len(pts); i++ {
+		p1, p2 := pts[i], pts[i+1]
+		if !overlaps(p1, p2) {
+			continue
+		}
+		horizontal := p1.Y == p2.Y
+		for _, shift := range []int{ROUTE_SPACING, -ROUTE_SPACING, 2 * ROUTE_SPACING, -2 * ROUTE_SPACING, 3 * ROUTE_SPACING, -3 * ROUTE_SPACING} {
+			q1, q2 := p1, p2
+			var valid bool
+			if horizontal {
+				q1.Y += shift
+				q2.Y += shift
+				valid = keepsDirection(pts[i-1].Y, p1.Y, q1.Y) && keepsDirection(pts[i+2].Y, p2.Y, q2.Y)
+			} else {
+				q1.X += shift
+				q2.X += shift
+				valid = keepsDirection(pts[i-1].X, p1.X, q1.X) && keepsDirection(pts[i+2].X, p2.X, q2.X)
+			}
+			// The segments from the source and to the target always touch their own resource
+			if !valid || overlaps(q1, q2) || crosses(q1, q2) || (i > 1 && crosses(pts[i-1], q1)) || (i+3 < len(pts) && crosses(q2, pts[i+2])) {
+				continue
+			}
+			log.Infof("Spread link segment %v-%v to %v-%v", p1, p2, q1, q2)
+			pts[i], pts[i+1] = q1, q2
+			break
+		}
+	}
+	for i := 0; i+1 < len(pts); i++ {
+		router.segments = append(router.segments, routedSegment{l, pts[i], pts[i+1]})
+	}
+	return pts
+}
@@ -0,0 +1,139 @@
+package types
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+func countBends(pts []image.Point) int {
+	bends := 0
+	for i := 1; i+1 < len(pts); i++ {
+		a, b, c := pts[i-1], pts[i], pts[i+1]
+		if (a.X == b.X) != (b.X == c.X) {
+			bends++
+		}
+	}
+	return bends
+}
+
+func TestFindRoute(t *testing.T) {
+	// A wall between start and end forces the path around it
+	obstacles := []image.Rectangle{image.Rect(40, -50, 60, 30)}
+	path, ok := findRoute(image.Pt(0, 0), image.Pt(100, 0), image.Pt(1, 0), image.Pt(1, 0), obstacles)
+	if !ok {
+		t.Fatal("expected a route")
+	}
+	// The path arrives at the end in the given direction
+	path = simplifyPath(append(path, image.Pt(110, 0)))
+	if path[0] != image.Pt(0, 0) || path[len(path)-2] != image.Pt(100, 0) {
+		t.Fatalf("expected the route from (0,0) to (100,0), got %v", path)
+	}
+	if bends := countBends(path); bends != 4 {
+		t.Errorf("expected 4 bends, got %d: %v", bends, path)
+	}
+	// The shorter way goes below the wall
+	for _, p := range path {
+		if p.Y < 0 {
+			t.Errorf("expected the route below the wall, got %v", path)
+		}
+	}
+
+	if _, ok := findRoute(image.Pt(0, 0), image.Pt(100, 0), image.Pt(1, 0), image.Pt(1, 0), []image.Rectangle{image.Rect(-10, -10, 10, 10), image.Rect(-20, -20, 20, 20)}); ok {
+		t.Error("expected no route out of an enclosed start")
+	}
+}
+
+func TestSimplifyPath(t *testing.T) {
+	pts := simplifyPath([]image.Point{{0, 0}, {0, 10}, {0, 10}, {0, 20}, {10, 20}, {20, 20}})
+	expected := []image.Point{{0, 0}, {0, 20}, {20, 20}}
+	if len(pts) != len(expected) {
+		t.Fatalf("expected %v, got %v", expected, pts)
+	}
+	for i := range expected {
+		if pts[i] != expected[i] {
+			t.Errorf("expected %v, got %v", expected, pts)
+		}
+	}
+}
+
+func TestRouteAroundResources(t *testing.T) {
+	canvas := new(Resource).Init()
+	source := newGridCell(40, 40)
+	source.SetBindings(image.Rect(0, 0, 40, 40))
+	obstacle := newGridCell(40, 40)
+	obstacle.SetBindings(image.Rect(100, 0, 140, 40))
+	target := newGridCell(40, 40)
+	target.SetBindings(image.Rect(200, 0, 240, 40))
+	for _, r := range []*Resource{source, obstacle, target} {
+		if err := canvas.AddChild(r); err != nil {
+			t.Fatalf("AddChild failed: %v", err)
+		}
+	}
+	canvas.SetBindings(image.Rect(0, 0, 240, 40))
+	router := NewRouter(canvas)
+
+	newLink := func() *Link {
+		link := Link{}.Init(source, WINDROSE_E, ArrowHead{}, target, WINDROSE_W, ArrowHead{}, 2, color.RGBA{0, 0, 0, 255})
+		link.SetType("orthogonal-routed")
+		link.SetRouter(router)
+		return link
+	}
+	first := newLink()
+	controlPts, ok := first.route(image.Pt(40, 20), image.Pt(200, 20))
+	if !ok {
+		t.Fatal("expected a route")
+	}
+	pts := append(append([]image.Point{{40, 20}}, controlPts...), image.Pt(200, 20))
+	for i := 0; i+1 < len(pts); i++ {
+		segment := image.Rect(pts[i].X, pts[i].Y, pts[i+1].X, pts[i+1].Y).Canon()
+		if segment.Overlaps(image.Rect(100-ROUTE_MARGIN, -ROUTE_MARGIN, 140+ROUTE_MARGIN, 40+ROUTE_MARGIN).Inset(1)) {
+			t.Errorf("expected the route around the obstacle, got %v", pts)
+		}
+	}
+	if bends := countBends(pts); bends != 4 {
+		t.Errorf("expected 4 bends, got %d: %v", bends, pts)
+	}
+
+	// A second link along the same way is moved apart
+	second := newLink()
+	secondPts, ok := second.route(image.Pt(40, 20), image.Pt(200, 20))
+	if !ok {
+		t.Fatal("expected a route")
+	}
+	if len(secondPts) != len(controlPts) {
+		t.Fatalf("expected the same shape, got %v and %v", controlPts, secondPts)
+	}
+	moved := false
+	for i := range controlPts {
+		if controlPts[i] != secondPts[i] {
+			moved = true
+		}
+	}
+	if !moved {
+		t.Errorf("expected parallel segments to be spread, got %v and %v", controlPts, secondPts)
+	}
+
+	// The links share the grid of the router, built once for the diagram
+	if router.grid == nil || first.routeGrid() != router.grid || second.routeGrid() != router.grid {
+		t.Error("expected the links to share the grid of the router")
+	}
+}
+
+func TestRouteGridCovering(t *testing.T) {
+	grid := newRouteGrid([]routeArea{{area: image.Rect(0, 0, 20, 20)}, {area: image.Rect(10, 10, 30, 30)}})
+	for _, tc := range []struct {
+		p        image.Point
+		expected int
+	}{
+		{image.Pt(5, 5), 1},
+		{image.Pt(15, 15), 2},
+		{image.Pt(25, 25), 1},
+		{image.Pt(25, 5), 0},
+		{image.Pt(40, 40), 0},
+	} {
+		if got := len(grid.covering(2*tc.p.X+1, 2*tc.p.Y+1)); got != tc.expected {
+			t.Errorf("%v: expected %d areas, got %d", tc.p, tc.expected, got)
+		}
+	}
+}
@@ -0,0 +1,180 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package types
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"math"
+
+	"github.com/awslabs/diagram-as-code/internal/vector"
+)
+
+// Shadow is a blurred copy of the frame of a resource drawn under it, as a CSS box-shadow
+type Shadow struct {
+	Offset image.Point
+	Blur   int // radius of the blur in pixels
+	Color  color.RGBA
+}
+
+// Gradient is a linear gradient fill. Angle is in degrees as for CSS linear-gradient: 0 runs from
+// the bottom to the top and 90 from the left to the right.
+type Gradient struct {
+	From  color.RGBA
+	To    color.RGBA
+	Angle float64
+}
+
+func (r *Resource) SetCornerRadius(radius int) {
+	r.cornerRadius = radius
+}
+
+func (r *Resource) SetShadow(shadow *Shadow) {
+	r.shadow = shadow
+}
+
+// SetGradientFill fills the resource with the gradient instead of its fill color
+func (r *Resource) SetGradientFill(gradient *Gradient) {
+	r.gradient = gradient
+}
+
+// frameRadius returns the corner radius of the background, no more than half of its sides
+func (r *Resource) frameRadius() float64 {
+	side := minInt(r.bindings.Dx(), r.bindings.Dy()) - 2
+	return math.Max(0, math.Min(float64(r.cornerRadius), float64(side)/2))
+}
+
+// roundedRectangle returns the outline of a rectangle with rounded corners, clockwise from the top
+// left corner
+func roundedRectangle(left, top, right, bottom, radius float64) polygon {
+	if radius <= 0 {
+		return polygon{vector.New(left, top), vector.New(right, top), vector.New(right, bottom), vector.New(left, bottom)}
+	}
+	corners := []struct {
+		center vector.Vector
+		angle  float64
+	}{
+		{vector.New(left+radius, top+radius), math.Pi},
+		{vector.New(right-radius, top+radius), 3 * math.Pi / 2},
+		{vector.New(right-radius, bottom-radius), 0},
+		{vector.New(left+radius, bottom-radius), math.Pi / 2},
+	}
+	// Segments about a pixel long
+	steps := maxInt(2, int(math.Ceil(math.Pi*radius/2)))
+	p := polygon{}
+	for _, corner := range corners {
+		for s := 0; s <= steps; s++ {
+			angle := corner.angle + math.Pi/2*float64(s)/float64(steps)
+			p = append(p, corner.center.Add(vector.New(math.Cos(angle), math.Sin(angle)).Scale(radius)))
+		}
+	}
+	return p
+}
+
+// linearGradient is an image of the colors of a gradient over the whole canvas
+type linearGradient struct {
+	from  color.RGBA
+	to    color.RGBA
+	start vector.Vector
+	dir   vector.Vector // from the start to the end of the gradient, divided by its length
+}
+
+// newLinearGradient returns the gradient spanning the rectangle, from corner to corner for
+// diagonal angles as CSS does
+func newLinearGradient(g *Gradient, r image.Rectangle) *linearGradient {
+	angle := g.Angle * math.Pi / 180
+	d := vector.New(math.Sin(angle), -math.Cos(angle))
+	length := math.Abs(float64(r.Dx())*d.X) + math.Abs(float64(r.Dy())*d.Y)
+	center := vector.New(float64(r.Min.X+r.Max.X-1)/2, float64(r.Min.Y+r.Max.Y-1)/2)
+	if length == 0 {
+		length = 1
+	}
+	return &linearGradient{
+		from:  premultiply(g.From),
+		to:    premultiply(g.To),
+		start: center.Sub(d.Scale(length / 2)),
+		dir:   d.Scale(1 / length),
+	}
+}
+
+func (g *linearGradient) ColorModel() color.Model {
+	return color.RGBAModel
+}
+
+func (g *linearGradient) Bounds() image.Rectangle {
+	return image.Rect(math.MinInt32, math.MinInt32, math.MaxInt32, math.MaxInt32)
+}
+
+func (g *linearGradient) At(x, y int) color.Color {
+	t := math.Max(0, math.Min(1, vector.New(float64(x), float64(y)).Sub(g.start).Dot(g.dir)))
+	mix := func(a, b uint8) uint8 {
+		return uint8(math.Round(float64(a)*(1-t) + float64(b)*t))
+	}
+	return color.RGBA{mix(g.from.R, g.to.R), mix(g.from.G, g.to.G), mix(g.from.B, g.to.B), mix(g.from.A, g.to.A)}
+}
+
+// premultiply converts a color of a DAC file, which isn't premultiplied, to a color.RGBA
+func premultiply(c color.RGBA) color.RGBA {
+	return color.RGBAModel.Convert(color.NRGBA(c)).(color.RGBA)
+}
+
+// drawShadow draws the shadow of the frame outline. The shadow is only visible outside of the
+// frame, so that it doesn't darken transparent backgrounds.
+func (r *Resource) drawShadow(img *image.RGBA, outline polygon) {
+	s := r.shadow
+	offset := vector.New(float64(s.Offset.X), float64(s.Offset.Y))
+	moved := make(polygon, len(outline))
+	for i, pt := range outline {
+		moved[i] = pt.Add(offset)
+	}
+	bounds := polygonBounds([]polygon{moved}).Inset(-s.Blur)
+	mask := image.NewAlpha(bounds)
+	rasterize(bounds, []polygon{moved}).Draw(mask, bounds, image.Opaque, image.Point{})
+	blurAlpha(mask, s.Blur/2)
+
+	frame := image.NewAlpha(bounds)
+	rasterize(bounds, []polygon{outline}).Draw(frame, bounds, image.Opaque, image.Point{})
+	for i, a := range frame.Pix {
+		mask.Pix[i] = uint8(int(mask.Pix[i]) * (255 - int(a)) / 255)
+	}
+	draw.DrawMask(img, bounds, image.NewUniform(color.NRGBA(s.Color)), image.Point{}, mask, bounds.Min, draw.Over)
+}
+
+// blurAlpha blurs the mask with three box blurs of the given radius each way, which is close to
+// a gaussian blur
+func blurAlpha(mask *image.Alpha, radius int) {
+	if radius <= 0 {
+		return
+	}
+	w, h := mask.Rect.Dx(), mask.Rect.Dy()
+	line := make([]int, maxInt(w, h))
+	// boxBlur blurs n values of the mask, from offset i by step, pixels outside being transparent
+	boxBlur := func(i, step, n int) {
+		for k := 0; k < n; k++ {
+			line[k] = int(mask.Pix[i+k*step])
+		}
+		sum := 0
+		for k := 0; k < radius && k < n; k++ {
+			sum += line[k]
+		}
+		for k := 0; k < n; k++ {
+			if k+radius < n {
+				sum += line[k+radius]
+			}
+			if k-radius-1 >= 0 {
+				sum -= line[k-radius-1]
+			}
+			mask.Pix[i+k*step] = uint8(sum / (2*radius + 1))
+		}
+	}
+	for pass := 0; pass < 3; pass++ {
+		for y := 0; y < h; y++ {
+			boxBlur(y*mask.Stride, 1, w)
+		}
+		for x := 0; x < w; x++ {
+			boxBlur(x, mask.Stride, h)
+		}
+	}
+}
@@ -0,0 +1,71 @@
+package types
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+func newStyledFrame() (*Resource, *image.RGBA) {
+	r := new(Resource).Init()
+	r.bindings = &image.Rectangle{image.Pt(10, 10), image.Pt(70, 50)}
+	r.SetBorderColor(color.RGBA{0, 0, 0, 255})
+	return r, image.NewRGBA(image.Rect(0, 0, 90, 70))
+}
+
+func TestRoundedCorners(t *testing.T) {
+	r, img := newStyledFrame()
+	r.SetFillColor(color.RGBA{255, 0, 0, 255})
+	r.SetCornerRadius(10)
+	r.drawFrame(img)
+	if img.RGBAAt(10, 10).A != 0 || img.RGBAAt(11, 11).A != 0 {
+		t.Error("expected the corner to be cut")
+	}
+	if c := img.RGBAAt(40, 10); c != (color.RGBA{0, 0, 0, 255}) {
+		t.Errorf("expected the border along the side, got %v", c)
+	}
+	if c := img.RGBAAt(14, 14); c != (color.RGBA{255, 0, 0, 255}) {
+		t.Errorf("expected the background inside the corner, got %v", c)
+	}
+
+	// The radius is no more than half of the shortest side
+	r.SetCornerRadius(1000)
+	if radius := r.frameRadius(); radius != 19 {
+		t.Errorf("expected the radius to be limited to 19, got %v", radius)
+	}
+}
+
+func TestGradientFill(t *testing.T) {
+	r, img := newStyledFrame()
+	r.SetGradientFill(&Gradient{From: color.RGBA{255, 0, 0, 255}, To: color.RGBA{0, 0, 255, 255}, Angle: 90})
+	r.drawFrame(img)
+	left, right := img.RGBAAt(12, 30), img.RGBAAt(67, 30)
+	if left.R < 240 || left.B > 15 || right.B < 240 || right.R > 15 {
+		t.Errorf("expected the gradient to run from red to blue, got %v and %v", left, right)
+	}
+	if top, bottom := img.RGBAAt(40, 12), img.RGBAAt(40, 47); top != bottom {
+		t.Errorf("expected the same color along a column, got %v and %v", top, bottom)
+	}
+
+	// A fill color replaces the gradient
+	r.SetFillColor(color.RGBA{0, 255, 0, 255})
+	r.drawFrame(img)
+	if c := img.RGBAAt(12, 30); c != (color.RGBA{0, 255, 0, 255}) {
+		t.Errorf("expected the fill color, got %v", c)
+	}
+}
+
+func TestShadow(t *testing.T) {
+	r, img := newStyledFrame()
+	r.SetShadow(&Shadow{Offset: image.Pt(4, 4), Blur: 4, Color: color.RGBA{0, 0, 0, 128}})
+	r.drawFrame(img)
+	if img.RGBAAt(40, 53).A == 0 || img.RGBAAt(73, 30).A == 0 {
+		t.Error("expected the shadow below and on the right of the frame")
+	}
+	if img.RGBAAt(40, 30).A != 0 {
+		t.Error("expected no shadow through the transparent background")
+	}
+	if img.RGBAAt(5, 30).A != 0 {
+		t.Error("expected no shadow on the left of the frame")
+	}
+}
@@ -75,6 +75,15 @@ func ConvertWindrose(position string) (Windrose, error) {
 	return 0, fmt.Errorf("unknown position: %s, supported positions are N, NNE, NE, ENE, E, ESE, SE, SSE, S, SSW, SW, WSW, W, WNW, NW, NNW, auto", position)
 }
 
+// String returns the position as written in DAC files, the reverse of ConvertWindrose
+func (w Windrose) String() string {
+	names := []string{"N", "NNE", "NE", "ENE", "E", "ESE", "SE", "SSE", "S", "SSW", "SW", "WSW", "W", "WNW", "NW", "NNW"}
+	if w < 0 || int(w) >= len(names) {
+		return "auto"
+	}
+	return names[w]
+}
+
 type Margin struct {
 	Top    int
 	Right  int
@@ -89,6 +98,30 @@ type Padding struct {
 	Left   int
 }
 
+// Spacing overrides the sides of a margin or a padding that are set, the others keep their default
+type Spacing struct {
+	Top    *int `yaml:"Top"`
+	Right  *int `yaml:"Right"`
+	Bottom *int `yaml:"Bottom"`
+	Left   *int `yaml:"Left"`
+}
+
+func (s Spacing) apply(m Margin) Margin {
+	if s.Top != nil {
+		m.Top = *s.Top
+	}
+	if s.Right != nil {
+		m.Right = *s.Right
+	}
+	if s.Bottom != nil {
+		m.Bottom = *s.Bottom
+	}
+	if s.Left != nil {
+		m.Left = *s.Left
+	}
+	return m
+}
+
 func _max(a, b uint32) uint32 {
 	if a > b {
 		return a
@@ -20,6 +20,7 @@ func (h VerticalStack) Init() *Resource {
 	sr.iconImage = image.NewRGBA(*sr.bindings)
 	sr.iconBounds = image.Rect(0, 0, 0, 0)
 	sr.borderColor = &color.RGBA{0, 0, 0, 0}
+	sr.borderWidth = WIDTH
 	sr.fillColor = color.RGBA{0, 0, 0, 0}
 	sr.label = ""
 	sr.labelColor = &color.RGBA{0, 0, 0, 0}
@@ -0,0 +1,105 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package types
+
+import (
+	"image"
+	"math"
+)
+
+// Waypoint is a point a link passes through. It is either fixed on the canvas or, when Resource
+// is set, at Position of the resource moved away from it by Offset.
+type Waypoint struct {
+	Point    image.Point
+	Resource *Resource
+	Position Windrose
+	Offset   int
+}
+
+func (l *Link) SetWaypoints(waypoints []Waypoint) {
+	l.waypoints = waypoints
+}
+
+// resolve returns the point on the canvas once the resources are laid out. A waypoint without
+// position is the center of its resource.
+func (w Waypoint) resolve() image.Point {
+	if w.Resource == nil {
+		return w.Point
+	}
+	b := w.Resource.GetBindings()
+	if w.Position == WINDROSE_AUTO {
+		return image.Point{(b.Min.X + b.Max.X) / 2, (b.Min.Y + b.Max.Y) / 2}
+	}
+	pt, _ := calcPosition(b, w.Position)
+	direction := (&Link{}).getDirectionVector(int(w.Position)).Scale(float64(w.Offset))
+	return pt.Add(image.Point{int(math.Round(direction.X)), int(math.Round(direction.Y))})
+}
+
+func (l *Link) waypointPoints() []image.Point {
+	pts := []image.Point{}
+	for _, w := range l.waypoints {
+		pts = append(pts, w.resolve())
+	}
+	return pts
+}
+
+// orthogonalWaypointPath returns the control points of an orthogonal path through the waypoints.
+// It leaves the source along the axis of SourcePosition and enters the target along the axis of
+// TargetPosition, with one bend between two waypoints that are not aligned. A waypoint behind the
+// side of the source or the target is reached around a short stub out of that side.
+func (l *Link) orthogonalWaypointPath(sourcePt, targetPt image.Point) []image.Point {
+	sourceDir := l.getDirectionVector(int(l.SourcePosition))
+	targetDir := l.getDirectionVector(int(l.TargetPosition))
+	sourceStep := image.Point{int(sourceDir.X), int(sourceDir.Y)}
+	targetStep := image.Point{int(targetDir.X), int(targetDir.Y)}
+	waypoints := l.waypointPoints()
+
+	horizontal := sourceStep.X != 0
+	pts := []image.Point{sourcePt}
+	if len(waypoints) != 0 && behind(waypoints[0], sourcePt, sourceStep) {
+		pts = append(pts, sourcePt.Add(sourceStep.Mul(ROUTE_STUB)))
+		horizontal = !horizontal
+	}
+	for _, p := range waypoints {
+		last := pts[len(pts)-1]
+		if last.X != p.X && last.Y != p.Y {
+			if horizontal {
+				pts = append(pts, image.Point{p.X, last.Y})
+			} else {
+				pts = append(pts, image.Point{last.X, p.Y})
+			}
+			horizontal = !horizontal
+		} else if last != p {
+			horizontal = last.Y == p.Y
+		}
+		pts = append(pts, p)
+	}
+
+	last := pts[len(pts)-1]
+	if behind(last, targetPt, targetStep) {
+		stub := targetPt.Add(targetStep.Mul(ROUTE_STUB))
+		if targetStep.X != 0 {
+			pts = append(pts, image.Point{stub.X, last.Y}, stub)
+		} else {
+			pts = append(pts, image.Point{last.X, stub.Y}, stub)
+		}
+	} else if last.X != targetPt.X && last.Y != targetPt.Y {
+		if targetStep.X != 0 {
+			pts = append(pts, image.Point{last.X, targetPt.Y})
+		} else {
+			pts = append(pts, image.Point{targetPt.X, last.Y})
+		}
+	}
+	pts = simplifyPath(append(pts, targetPt))
+	if len(pts) < 2 {
+		return nil
+	}
+	return pts[1 : len(pts)-1]
+}
+
+// behind reports whether p is not in front of the side of a resource at pt facing step
+func behind(p, pt, step image.Point) bool {
+	d := p.Sub(pt)
+	return d.X*step.X+d.Y*step.Y <= 0
+}
@@ -0,0 +1,67 @@
+package types
+
+import (
+	"image"
+	"image/color"
+	"reflect"
+	"testing"
+)
+
+func TestWaypointResolve(t *testing.T) {
+	resource := new(Resource).Init()
+	resource.SetBindings(image.Rect(100, 100, 164, 164))
+
+	tests := []struct {
+		waypoint Waypoint
+		expected image.Point
+	}{
+		{Waypoint{Point: image.Pt(10, 20)}, image.Pt(10, 20)},
+		{Waypoint{Resource: resource, Position: WINDROSE_S, Offset: 20}, image.Pt(132, 184)},
+		{Waypoint{Resource: resource, Position: WINDROSE_W, Offset: 10}, image.Pt(90, 132)},
+		{Waypoint{Resource: resource, Position: WINDROSE_AUTO, Offset: 10}, image.Pt(132, 132)},
+	}
+	for _, tt := range tests {
+		if p := tt.waypoint.resolve(); p != tt.expected {
+			t.Errorf("expected %v for %+v, got %v", tt.expected, tt.waypoint, p)
+		}
+	}
+}
+
+func TestOrthogonalWaypointPath(t *testing.T) {
+	newLink := func(sourcePosition, targetPosition Windrose, waypoints ...image.Point) *Link {
+		link := Link{}.Init(new(Resource).Init(), sourcePosition, ArrowHead{}, new(Resource).Init(), targetPosition, ArrowHead{}, 2, color.RGBA{0, 0, 0, 255})
+		for _, p := range waypoints {
+			link.waypoints = append(link.waypoints, Waypoint{Point: p})
+		}
+		return link
+	}
+
+	tests := []struct {
+		name     string
+		link     *Link
+		expected []image.Point
+	}{
+		{
+			name:     "one bend on each side of the waypoint",
+			link:     newLink(WINDROSE_E, WINDROSE_N, image.Pt(100, 50)),
+			expected: []image.Point{{100, 0}, {100, 50}, {200, 50}},
+		},
+		{
+			name:     "aligned waypoint",
+			link:     newLink(WINDROSE_E, WINDROSE_W, image.Pt(100, 0)),
+			expected: []image.Point{{100, 0}, {100, 100}},
+		},
+		{
+			name:     "waypoint behind the target",
+			link:     newLink(WINDROSE_E, WINDROSE_S, image.Pt(100, 50)),
+			expected: []image.Point{{100, 0}, {100, 100 + ROUTE_STUB}, {200, 100 + ROUTE_STUB}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if pts := tt.link.orthogonalWaypointPath(image.Pt(0, 0), image.Pt(200, 100)); !reflect.DeepEqual(pts, tt.expected) {
+				t.Errorf("expected %v, got %v", tt.expected, pts)
+			}
+		})
+	}
+}